@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/therako/universal-studios/clock"
+)
+
+// Role of the caller, decides which routes can be called
+type Role string
+
+// Roles
+const (
+	// RoleAdmin manages rides and can call every route
+	RoleAdmin Role = "admin"
+	// RoleOperator runs the rides, eg. marks a ride as down
+	RoleOperator Role = "operator"
+	// RoleGate runs the studio gates, letting customers in & out
+	RoleGate Role = "gate"
+	// RoleGuest is a customer inside the studio, only allowed to act as themselves
+	RoleGuest Role = "guest"
+)
+
+// Errors
+var (
+	ErrUnauthenticated = errors.New("Missing or invalid credentials")
+	ErrForbidden       = errors.New("Not allowed to access this route")
+	ErrInvalidToken    = errors.New("Invalid token")
+	ErrTokenExpired    = errors.New("Token has expired")
+)
+
+// principalContextKey holds the caller in the request's context
+type principalContextKey struct{}
+
+// principal is the authenticated caller of a request
+type principal struct {
+	Role Role
+	// CustomerID is set only for guests
+	CustomerID uint
+}
+
+// authenticator verifies API keys & signed tokens locally, no external IdP is involved
+type authenticator struct {
+	keys      map[string]Role
+	jwtSecret []byte
+	guestTTL  time.Duration
+	disabled  bool
+	// clock tells when guest tokens expire, the real clock when nil
+	clock clock.Clock
+}
+
+func newAuthenticator(config Config, clk clock.Clock) *authenticator {
+	// Config is validated on load, so errors are not expected here
+	keys, _ := config.apiKeys()
+	return &authenticator{
+		keys:      keys,
+		jwtSecret: []byte(config.JWTSecret),
+		guestTTL:  time.Duration(config.GuestTokenTTLSecs) * time.Second,
+		disabled:  config.AuthDisabled,
+		clock:     clk,
+	}
+}
+
+// enabled is false only when auth is explicitly disabled, leaving the API open. Without API keys
+// or a token secret nobody can authenticate, so every route but the public ones is refused.
+func (a *authenticator) enabled() bool {
+	return !a.disabled
+}
+
+// authenticate finds the caller from `X-API-Key` or a `Authorization: Bearer` token
+func (a *authenticator) authenticate(c *gin.Context) (*principal, error) {
+	return a.authenticateCredentials(c.GetHeader("X-API-Key"), c.GetHeader("Authorization"))
+}
+
+// authenticateCredentials finds the caller from an API key or the authorization header value
+func (a *authenticator) authenticateCredentials(apiKey string, authorization string) (*principal, error) {
+	if apiKey != "" {
+		role, ok := a.keys[apiKey]
+		if !ok {
+			return nil, ErrUnauthenticated
+		}
+		return &principal{Role: role}, nil
+	}
+
+	if strings.HasPrefix(authorization, "Bearer ") && len(a.jwtSecret) > 0 {
+		return a.verifyToken(strings.TrimPrefix(authorization, "Bearer "))
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// require returns a middleware allowing only the given roles, admin is always allowed
+func (a *authenticator) require(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.enabled() {
+			return
+		}
+
+		p, err := a.authenticate(c)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, CodeUnauthenticated, err.Error())
+			return
+		}
+
+		if !p.allows(roles) {
+			abortWithError(c, http.StatusForbidden, CodeForbidden, ErrForbidden.Error())
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), principalContextKey{}, p))
+	}
+}
+
+// allows reports if the caller can call a route open to the roles, admin is always allowed
+func (p *principal) allows(roles []Role) bool {
+	return p.Role == RoleAdmin || hasRole(roles, p.Role)
+}
+
+// actsAs reports if the caller can act as one of the customers, only guests are restricted
+func (p *principal) actsAs(customerIDs ...uint) bool {
+	if p.Role != RoleGuest {
+		return true
+	}
+	for _, id := range customerIDs {
+		if id == p.CustomerID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	Role      Role   `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// issueGuestToken signs a JWT (HS256) for a customer to call the guest routes as themselves
+func (a *authenticator) issueGuestToken(customerID uint) (string, error) {
+	claims, err := json.Marshal(tokenClaims{
+		Subject:   strconv.Itoa(int(customerID)),
+		Role:      RoleGuest,
+		ExpiresAt: clock.Or(a.clock).Now().Add(a.guestTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + a.sign(unsigned), nil
+}
+
+func (a *authenticator) verifyToken(token string) (*principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(a.sign(parts[0]+"."+parts[1])), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &tokenClaims{}
+	err = json.Unmarshal(data, claims)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if clock.Or(a.clock).Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	p := &principal{Role: claims.Role}
+	if claims.Role == RoleGuest {
+		id, err := strconv.Atoi(claims.Subject)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		p.CustomerID = uint(id)
+	}
+	return p, nil
+}
+
+func (a *authenticator) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseAPIKeys reads keys in the form `key1:role,key2:role`
+func parseAPIKeys(value string) (map[string]Role, error) {
+	keys := map[string]Role{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, expected key:role", entry)
+		}
+		role := Role(parts[1])
+		if !hasRole([]Role{RoleAdmin, RoleOperator, RoleGate, RoleGuest}, role) {
+			return nil, fmt.Errorf("unknown role %q for API key", role)
+		}
+		keys[parts[0]] = role
+	}
+	return keys, nil
+}
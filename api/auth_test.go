@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gotest.tools/v3/assert"
+)
+
+var authConfig = api.Config{
+	HTTPPort:          8081,
+	APIKeys:           "admin-key:admin,operator-key:operator,gate-key:gate",
+	JWTSecret:         "secret",
+	GuestTokenTTLSecs: 60,
+
+	CustomerCacheNumCounters: 1000,
+	CustomerCacheMaxCost:     1 << 20,
+	RideCacheNumCounters:     1000,
+	RideCacheMaxCost:         1 << 20,
+}
+
+func TestAuthFailsClosed(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), api.Config{HTTPPort: 8081}, db)
+
+	assert.Equal(t, 401, serveJSON(router, "GET", "/v1/rides", "").Code, "expected no credentials to refuse everyone")
+	assert.Equal(t, 401, serveJSON(router, "POST", "/v1/rides", `{"name":"Open","capacity":1,"ride_time_secs":60}`).Code)
+	assert.Equal(t, 200, serveJSON(router, "GET", "/healthz", "").Code, "expected probes to stay public")
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), authConfig, db)
+	form := url.Values{}
+	form.Add("name", "DareDevil")
+	form.Add("ride_time_secs", "300")
+	form.Add("capacity", "20")
+
+	tests := []struct {
+		name     string
+		key      string
+		expected int
+	}{
+		{"error without key", "", 401},
+		{"error with unknown key", "random", 401},
+		{"error when role is not allowed", "gate-key", 403},
+		{"expected admin to add rides", "admin-key", 200},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/ride/add", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.key != "" {
+				req.Header.Set("X-API-Key", test.key)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expected, w.Code)
+		})
+	}
+}
+
+func TestGuestTokenAuth(t *testing.T) {
+	db := testDB(t.Name())
+	tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
+	db.Create(&customers.Customer{Model: models.Model{ID: 200}})
+	db.Create(&rides.Ride{Model: models.Model{ID: 2001}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	router := api.New(context.Background(), authConfig, db)
+
+	form := url.Values{}
+	form.Add("code", "abc")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-API-Key", "gate-key")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	response := struct {
+		CustomerID uint   `json:"customer_id"`
+		Token      string `json:"token"`
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Assert(t, response.Token != "")
+
+	queue := func(customerID, token string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("id", customerID)
+		form.Add("ride_id", "2001")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/queue", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("error when guest queues someone else", func(t *testing.T) {
+		w := queue("200", response.Token)
+		assert.Equal(t, 403, w.Code)
+		assert.Equal(t, `{"err":"Not allowed to access this route"}`, w.Body.String())
+	})
+
+	t.Run("error on tampered token", func(t *testing.T) {
+		w := queue("201", response.Token+"x")
+		assert.Equal(t, 401, w.Code)
+		assert.Equal(t, `{"err":"Invalid token"}`, w.Body.String())
+	})
+
+	t.Run("expected guest to queue themselves", func(t *testing.T) {
+		assert.Equal(t, uint(201), response.CustomerID)
+		w := queue("201", response.Token)
+		assert.Equal(t, 200, w.Code)
+	})
+
+	t.Run("error when guest calls staff routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/customer", nil)
+		req.Header.Set("Authorization", "Bearer "+response.Token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 403, w.Code)
+	})
+}
+
+func TestGuestTokenExpiry(t *testing.T) {
+	db := testDB(t.Name())
+	config, clock := withFakeClock(authConfig)
+	tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: clock.Now().Add(-time.Hour), ValidTill: clock.Now().Add(time.Hour)})
+	router := api.New(context.Background(), config, db)
+	w := serveJSON(router, "POST", "/v1/customers", `{"code":"abc"}`, "X-API-Key", "gate-key")
+	assert.Equal(t, 200, w.Code)
+	response := api.EnterResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	bearer := "Bearer " + response.Token
+
+	clock.Advance(59 * time.Second)
+	assert.Equal(t, 200, serveJSON(router, "GET", "/v1/rides", "", "Authorization", bearer).Code)
+
+	clock.Advance(time.Second)
+	w = serveJSON(router, "GET", "/v1/rides", "", "Authorization", bearer)
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `{"error":{"code":"unauthenticated","message":"Token has expired"}}`, w.Body.String())
+}
@@ -2,31 +2,359 @@ package api
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/drivers"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gitlab.com/therako/universal-studios/logging"
+	"gitlab.com/therako/universal-studios/tracing"
 )
 
+// ConfigName of the config file read from the working dir or /etc/universal-studios, eg. universal-studios.yaml.
+// CONFIG_FILE points at a file elsewhere. Env vars take precedence over the file.
+const ConfigName = "universal-studios"
+
+// Errors
+var (
+	ErrInvalidConfig = errors.New("Invalid config")
+)
+
+// sslModes postgres accepts
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
 // Config defines all possible values the studios service expects
 type Config struct {
 	HTTPPort uint `mapstructure:"HTTP_PORT"`
+	// GRPCPort serves the gRPC API for internal services, eg. turnstiles & ride PLCs
+	GRPCPort uint `mapstructure:"GRPC_PORT"`
+	// APIKeys for staff in the form `key1:role,key2:role`, roles are admin, operator, gate or guest
+	APIKeys string `mapstructure:"API_KEYS"`
+	// JWTSecret signs tokens issued to guests on entry. One of this or APIKeys is required unless
+	// AuthDisabled is set
+	JWTSecret         string `mapstructure:"JWT_SECRET"`
+	GuestTokenTTLSecs uint   `mapstructure:"GUEST_TOKEN_TTL_SECS"`
+	// IdempotencyWindowSecs is how long responses are replayed for a repeated Idempotency-Key, 0 disables it
+	IdempotencyWindowSecs uint `mapstructure:"IDEMPOTENCY_WINDOW_SECS"`
+	// TraceExporter spans are sent to, one of none, stdout or otlp
+	TraceExporter string `mapstructure:"TRACE_EXPORTER"`
+	// TraceOTLPEndpoint is the host:port of the OTLP HTTP collector, used with the otlp exporter
+	TraceOTLPEndpoint string `mapstructure:"TRACE_OTLP_ENDPOINT"`
+	// ShutdownTimeoutSecs is how long in-flight requests & background workers are waited on when shutting down
+	ShutdownTimeoutSecs uint `mapstructure:"SHUTDOWN_TIMEOUT_SECS"`
+	// LogLevel is one of debug, info, warn or error
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+
+	// DBDriver is one of postgres, mysql or sqlite, the POSTGRES_*, MYSQL_* or SQLITE_* settings of the
+	// driver are used to connect
+	DBDriver string `mapstructure:"DB_DRIVER"`
+
+	PostgresHost     string `mapstructure:"POSTGRES_HOST"`
+	PostgresPort     uint   `mapstructure:"POSTGRES_PORT"`
+	PostgresUser     string `mapstructure:"POSTGRES_USER"`
+	PostgresPassword string `mapstructure:"POSTGRES_PASSWORD"`
+	PostgresDB       string `mapstructure:"POSTGRES_DB"`
+	// PostgresSSLMode is one of disable, allow, prefer, require, verify-ca or verify-full
+	PostgresSSLMode string `mapstructure:"POSTGRES_SSL_MODE"`
+	// PostgresStatementTimeoutMs aborts statements running longer, 0 for no timeout
+	PostgresStatementTimeoutMs uint `mapstructure:"POSTGRES_STATEMENT_TIMEOUT_MS"`
+	PostgresConnectTimeoutSecs uint `mapstructure:"POSTGRES_CONNECT_TIMEOUT_SECS"`
+
+	MySQLHost     string `mapstructure:"MYSQL_HOST"`
+	MySQLPort     uint   `mapstructure:"MYSQL_PORT"`
+	MySQLUser     string `mapstructure:"MYSQL_USER"`
+	MySQLPassword string `mapstructure:"MYSQL_PASSWORD"`
+	MySQLDB       string `mapstructure:"MYSQL_DB"`
+	// MySQLTimeoutSecs bounds connecting, reads & writes
+	MySQLTimeoutSecs uint `mapstructure:"MYSQL_TIMEOUT_SECS"`
+
+	// SQLitePath of the DB file, created if missing
+	SQLitePath string `mapstructure:"SQLITE_PATH"`
+
+	// DBMaxOpenConns of the pool, 0 for no limit
+	DBMaxOpenConns uint `mapstructure:"DB_MAX_OPEN_CONNS"`
+	// DBMaxIdleConns kept in the pool, at most DBMaxOpenConns when that's set
+	DBMaxIdleConns uint `mapstructure:"DB_MAX_IDLE_CONNS"`
+	// DBConnMaxLifetimeSecs before a connection is closed & re-opened, 0 to keep connections open
+	DBConnMaxLifetimeSecs uint `mapstructure:"DB_CONN_MAX_LIFETIME_SECS"`
+
+	// CustomerCacheNumCounters is the no of keys whose access frequency is tracked, ~10x the customers expected to be cached
+	CustomerCacheNumCounters int64 `mapstructure:"CUSTOMER_CACHE_NUM_COUNTERS"`
+	CustomerCacheMaxCost     int64 `mapstructure:"CUSTOMER_CACHE_MAX_COST"`
+	// RideCacheNumCounters is the no of keys whose access frequency is tracked, ~10x the rides expected to be cached
+	RideCacheNumCounters int64 `mapstructure:"RIDE_CACHE_NUM_COUNTERS"`
+	RideCacheMaxCost     int64 `mapstructure:"RIDE_CACHE_MAX_COST"`
+
+	// AuthDisabled serves every route without authentication, eg. for local development
+	AuthDisabled bool `mapstructure:"AUTH_DISABLED"`
+	// GRPCDisabled stops serving the gRPC API
+	GRPCDisabled bool `mapstructure:"GRPC_DISABLED"`
+	// MetricsDisabled stops serving Prometheus metrics at /metrics
+	MetricsDisabled bool `mapstructure:"METRICS_DISABLED"`
+	// LegacyRoutesDisabled stops serving the routes before v1, once no clients use them
+	LegacyRoutesDisabled bool `mapstructure:"LEGACY_ROUTES_DISABLED"`
+	// MigrateOnStartDisabled stops applying pending migrations when serving, leaving it to the
+	// `migrate` command
+	MigrateOnStartDisabled bool `mapstructure:"MIGRATE_ON_START_DISABLED"`
+
+	// Clock the services, guest tokens & idempotency window tell the time with, the real clock when
+	// nil. It isn't read from the config, tests & simulations set a fake one.
+	Clock clock.Clock `mapstructure:"-"`
+}
+
+func (c Config) apiKeys() (map[string]Role, error) {
+	return parseAPIKeys(c.APIKeys)
+}
+
+// SlogLevel is the level logs are written at
+func (c Config) SlogLevel() (level slog.Level, err error) {
+	err = level.UnmarshalText([]byte(c.LogLevel))
+	return
+}
+
+// PostgresDSN is the connection URL of the configured postgres DB
+func (c Config) PostgresDSN() string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.PostgresUser, c.PostgresPassword),
+		Host:   fmt.Sprintf("%s:%d", c.PostgresHost, c.PostgresPort),
+		Path:   c.PostgresDB,
+		RawQuery: url.Values{
+			"statement_timeout": {fmt.Sprint(c.PostgresStatementTimeoutMs)},
+			"connect_timeout":   {fmt.Sprint(c.PostgresConnectTimeoutSecs)},
+			"sslmode":           {c.PostgresSSLMode},
+		}.Encode(),
+	}
+	return dsn.String()
+}
+
+// MySQLDSN is the data source name of the configured MySQL DB
+func (c Config) MySQLDSN() string {
+	timeout := fmt.Sprintf("%ds", c.MySQLTimeoutSecs)
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s", c.MySQLUser, c.MySQLPassword, c.MySQLHost, c.MySQLPort, c.MySQLDB, url.Values{
+		"parseTime":    {"true"},
+		"loc":          {"UTC"},
+		"charset":      {"utf8mb4"},
+		"timeout":      {timeout},
+		"readTimeout":  {timeout},
+		"writeTimeout": {timeout},
+	}.Encode())
+}
+
+// DSN of the configured driver's DB
+func (c Config) DSN() string {
+	switch c.DBDriver {
+	case drivers.MySQL:
+		return c.MySQLDSN()
+	case drivers.SQLite:
+		return c.SQLitePath
+	default:
+		return c.PostgresDSN()
+	}
+}
+
+// DBPool is the connection pool settings
+func (c Config) DBPool() drivers.Pool {
+	return drivers.Pool{
+		MaxOpenConns:    int(c.DBMaxOpenConns),
+		MaxIdleConns:    int(c.DBMaxIdleConns),
+		ConnMaxLifetime: time.Duration(c.DBConnMaxLifetimeSecs) * time.Second,
+	}
+}
+
+// Validate returns all the invalid values of the config
+func (c Config) Validate() error {
+	problems := []string{}
+	invalid := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.HTTPPort == 0 || c.HTTPPort > 65535 {
+		invalid("HTTP_PORT must be a port number")
+	}
+	if !c.GRPCDisabled && (c.GRPCPort == 0 || c.GRPCPort > 65535) {
+		invalid("GRPC_PORT must be a port number")
+	}
+	if !c.GRPCDisabled && c.GRPCPort == c.HTTPPort {
+		invalid("GRPC_PORT must differ from HTTP_PORT")
+	}
+	if _, err := c.apiKeys(); err != nil {
+		invalid("API_KEYS %s", err)
+	}
+	if !c.AuthDisabled && c.APIKeys == "" && c.JWTSecret == "" {
+		invalid("API_KEYS or JWT_SECRET is required, set AUTH_DISABLED to serve without auth")
+	}
+	if c.JWTSecret != "" && c.GuestTokenTTLSecs == 0 {
+		invalid("GUEST_TOKEN_TTL_SECS must be positive when JWT_SECRET is set")
+	}
+	if !tracing.ValidExporter(c.TraceExporter) {
+		invalid("TRACE_EXPORTER must be none, stdout or otlp")
+	}
+	if c.TraceExporter == tracing.ExporterOTLP && c.TraceOTLPEndpoint == "" {
+		invalid("TRACE_OTLP_ENDPOINT is required with the otlp exporter")
+	}
+	if c.ShutdownTimeoutSecs == 0 {
+		invalid("SHUTDOWN_TIMEOUT_SECS must be positive")
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		invalid("LOG_LEVEL must be debug, info, warn or error")
+	}
+
+	switch c.DBDriver {
+	case drivers.Postgres:
+		if c.PostgresHost == "" {
+			invalid("POSTGRES_HOST is required")
+		}
+		if c.PostgresPort == 0 || c.PostgresPort > 65535 {
+			invalid("POSTGRES_PORT must be a port number")
+		}
+		if c.PostgresUser == "" {
+			invalid("POSTGRES_USER is required")
+		}
+		if c.PostgresDB == "" {
+			invalid("POSTGRES_DB is required")
+		}
+		if !sslModes[c.PostgresSSLMode] {
+			invalid("POSTGRES_SSL_MODE must be one of disable, allow, prefer, require, verify-ca or verify-full")
+		}
+	case drivers.MySQL:
+		if c.MySQLHost == "" {
+			invalid("MYSQL_HOST is required")
+		}
+		if c.MySQLPort == 0 || c.MySQLPort > 65535 {
+			invalid("MYSQL_PORT must be a port number")
+		}
+		if c.MySQLUser == "" {
+			invalid("MYSQL_USER is required")
+		}
+		if c.MySQLDB == "" {
+			invalid("MYSQL_DB is required")
+		}
+	case drivers.SQLite:
+		if c.SQLitePath == "" {
+			invalid("SQLITE_PATH is required")
+		}
+	default:
+		invalid("DB_DRIVER must be postgres, mysql or sqlite")
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		invalid("DB_MAX_IDLE_CONNS must be at most DB_MAX_OPEN_CONNS")
+	}
+
+	if c.CustomerCacheNumCounters <= 0 || c.CustomerCacheMaxCost <= 0 {
+		invalid("CUSTOMER_CACHE_NUM_COUNTERS & CUSTOMER_CACHE_MAX_COST must be positive")
+	}
+	if c.RideCacheNumCounters <= 0 || c.RideCacheMaxCost <= 0 {
+		invalid("RIDE_CACHE_NUM_COUNTERS & RIDE_CACHE_MAX_COST must be positive")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// redacted returns a copy of the config safe to be logged
+func (c Config) redacted() Config {
+	if c.APIKeys != "" {
+		c.APIKeys = "<redacted>"
+	}
+	if c.JWTSecret != "" {
+		c.JWTSecret = "<redacted>"
+	}
+	if c.PostgresPassword != "" {
+		c.PostgresPassword = "<redacted>"
+	}
+	if c.MySQLPassword != "" {
+		c.MySQLPassword = "<redacted>"
+	}
+	return c
 }
 
 func setDefaultConfigs() {
 	viper.SetDefault("HTTP_PORT", 8080)
+	viper.SetDefault("GRPC_PORT", 9090)
+	viper.SetDefault("API_KEYS", "")
+	viper.SetDefault("JWT_SECRET", "")
+	viper.SetDefault("GUEST_TOKEN_TTL_SECS", 24*60*60)
+	viper.SetDefault("IDEMPOTENCY_WINDOW_SECS", 24*60*60)
+	viper.SetDefault("TRACE_EXPORTER", tracing.ExporterNone)
+	viper.SetDefault("TRACE_OTLP_ENDPOINT", "localhost:4318")
+	viper.SetDefault("SHUTDOWN_TIMEOUT_SECS", 30)
+	viper.SetDefault("LOG_LEVEL", "info")
+
+	viper.SetDefault("DB_DRIVER", drivers.Postgres)
+	viper.SetDefault("POSTGRES_HOST", "localhost")
+	viper.SetDefault("POSTGRES_PORT", 5432)
+	viper.SetDefault("POSTGRES_USER", "postgres")
+	viper.SetDefault("POSTGRES_PASSWORD", "")
+	viper.SetDefault("POSTGRES_DB", "postgres")
+	viper.SetDefault("POSTGRES_SSL_MODE", "disable")
+	viper.SetDefault("POSTGRES_STATEMENT_TIMEOUT_MS", 2000)
+	viper.SetDefault("POSTGRES_CONNECT_TIMEOUT_SECS", 1)
+	viper.SetDefault("MYSQL_HOST", "localhost")
+	viper.SetDefault("MYSQL_PORT", 3306)
+	viper.SetDefault("MYSQL_USER", "root")
+	viper.SetDefault("MYSQL_PASSWORD", "")
+	viper.SetDefault("MYSQL_DB", "studios")
+	viper.SetDefault("MYSQL_TIMEOUT_SECS", 2)
+	viper.SetDefault("SQLITE_PATH", "universal-studios.db")
+
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 20)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME_SECS", 30*60)
+
+	viper.SetDefault("CUSTOMER_CACHE_NUM_COUNTERS", customersEvents.DefaultCacheNumCounters)
+	viper.SetDefault("CUSTOMER_CACHE_MAX_COST", customersEvents.DefaultCacheMaxCost)
+	viper.SetDefault("RIDE_CACHE_NUM_COUNTERS", ridesEvents.DefaultCacheNumCounters)
+	viper.SetDefault("RIDE_CACHE_MAX_COST", ridesEvents.DefaultCacheMaxCost)
+
+	viper.SetDefault("AUTH_DISABLED", false)
+	viper.SetDefault("GRPC_DISABLED", false)
+	viper.SetDefault("METRICS_DISABLED", false)
+	viper.SetDefault("LEGACY_ROUTES_DISABLED", false)
+	viper.SetDefault("MIGRATE_ON_START_DISABLED", false)
 }
 
+// GetConfig reads the config from the config file, if there's one, & env vars & validates it
 func GetConfig(ctx context.Context) (cfg Config, err error) {
-	viper.SetConfigName("universal-studios")
-	viper.AutomaticEnv()
+	v := viper.GetViper()
+	v.SetConfigName(ConfigName)
+	v.AddConfigPath(".")
+	v.AddConfigPath("/etc/universal-studios")
+	v.AutomaticEnv()
+	if file := v.GetString("CONFIG_FILE"); file != "" {
+		v.SetConfigFile(file)
+	}
 	setDefaultConfigs()
 
-	all := viper.AllSettings()
+	if err = v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return cfg, fmt.Errorf("reading config file: %w", err)
+		}
+		err = nil
+	}
+
+	// Keys are matched regardless of case, eg. http_port in the config file
+	all := v.AllSettings()
 	if err = mapstructure.WeakDecode(all, &cfg); err != nil {
 		return
 	}
+	if err = cfg.Validate(); err != nil {
+		return
+	}
 
-	log.Printf("Initiating app with configs: %+v\n", cfg)
+	logging.FromContext(ctx).Info("Initiating app", "config", cfg.redacted(), "config_file", v.ConfigFileUsed())
 	return
 }
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"gitlab.com/therako/universal-studios/api"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	dir, err := ioutil.TempDir("", "config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "universal-studios.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(strings.Join([]string{
+		"http_port: 8181",
+		"log_level: debug",
+		"api_keys: admin-key:admin",
+		"postgres_host: db",
+		"postgres_password: p@ss word",
+		"customer_cache_num_counters: 1000",
+		"legacy_routes_disabled: true",
+	}, "\n")), 0600))
+
+	os.Setenv("CONFIG_FILE", file)
+	os.Setenv("HTTP_PORT", "8282")
+	defer os.Unsetenv("CONFIG_FILE")
+	defer os.Unsetenv("HTTP_PORT")
+
+	cfg, err := api.GetConfig(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, uint(8282), cfg.HTTPPort, "expected env vars to take precedence over the file")
+	assert.Equal(t, uint(9090), cfg.GRPCPort)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, int64(1000), cfg.CustomerCacheNumCounters)
+	assert.Equal(t, int64(1e7), cfg.RideCacheNumCounters)
+	assert.Equal(t, true, cfg.LegacyRoutesDisabled)
+	assert.Equal(t, "postgres://postgres:p%40ss%20word@db:5432/postgres?connect_timeout=1&sslmode=disable&statement_timeout=2000", cfg.PostgresDSN())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := api.Config{
+		HTTPPort:                 8080,
+		GRPCPort:                 8080,
+		ShutdownTimeoutSecs:      30,
+		LogLevel:                 "loud",
+		DBDriver:                 "postgres",
+		PostgresHost:             "localhost",
+		PostgresPort:             5432,
+		PostgresUser:             "postgres",
+		PostgresDB:               "postgres",
+		PostgresSSLMode:          "sometimes",
+		DBMaxOpenConns:           5,
+		DBMaxIdleConns:           10,
+		CustomerCacheNumCounters: 1000,
+		CustomerCacheMaxCost:     1000,
+		RideCacheNumCounters:     1000,
+		RideCacheMaxCost:         0,
+	}
+	err := cfg.Validate()
+	assert.Assert(t, errors.Is(err, api.ErrInvalidConfig))
+	for _, expected := range []string{"GRPC_PORT", "API_KEYS or JWT_SECRET", "LOG_LEVEL", "POSTGRES_SSL_MODE", "DB_MAX_IDLE_CONNS", "RIDE_CACHE"} {
+		assert.ErrorContains(t, err, expected)
+	}
+
+	cfg.GRPCDisabled = true
+	cfg.LogLevel = "warn"
+	cfg.PostgresSSLMode = "require"
+	cfg.DBMaxIdleConns = 5
+	cfg.RideCacheMaxCost = 1000
+	cfg.APIKeys = "admin-key:admin"
+	assert.NilError(t, cfg.Validate())
+
+	t.Run("expected auth to be off only when explicitly disabled", func(t *testing.T) {
+		cfg := cfg
+		cfg.APIKeys = ""
+		assert.ErrorContains(t, cfg.Validate(), "AUTH_DISABLED")
+		cfg.AuthDisabled = true
+		assert.NilError(t, cfg.Validate())
+	})
+
+	t.Run("expected the settings of the chosen DB driver to be checked", func(t *testing.T) {
+		cfg := cfg
+		cfg.DBDriver = "oracle"
+		assert.ErrorContains(t, cfg.Validate(), "DB_DRIVER")
+
+		cfg.DBDriver = "sqlite"
+		assert.ErrorContains(t, cfg.Validate(), "SQLITE_PATH")
+		cfg.SQLitePath = "studios.db"
+		assert.NilError(t, cfg.Validate())
+		assert.Equal(t, "studios.db", cfg.DSN())
+	})
+}
+
+func TestFeatureToggles(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), api.Config{MetricsDisabled: true, LegacyRoutesDisabled: true, AuthDisabled: true}, db)
+	assert.Equal(t, 404, serveJSON(router, "GET", "/metrics", "").Code)
+	assert.Equal(t, 404, serveJSON(router, "GET", "/ride", "").Code)
+	assert.Equal(t, 200, serveJSON(router, "GET", "/v1/rides", "").Code)
+}
@@ -1,44 +1,69 @@
 package api
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gitlab.com/therako/universal-studios/data/customers"
-	"gitlab.com/therako/universal-studios/data/events"
-	"gitlab.com/therako/universal-studios/data/rides"
-	customersEvents "gitlab.com/therako/universal-studios/events/customers"
 )
 
 type Customers struct {
-	DAO      customers.DAO
-	RideDAO  rides.DAO
-	eventDAO events.DAO
+	operations
 }
 
-// List returns a list of all customers inside the studio
+// List returns a page of customers inside the studio by default, the next page's cursor is sent
+// in the X-Next-Cursor header
 func (r Customers) List(c *gin.Context) {
-	customers, err := r.DAO.List()
+	var query CustomerListQuery
+	err := c.ShouldBindQuery(&query)
 	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
-		log.Println(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	filter, err := query.filter()
+	if err != nil {
+		handleError(c, err, "customers")
+		return
+	}
+
+	customers, next, err := r.CustomerDAO.List(filter)
+	if err != nil {
+		handleError(c, err, "customers")
 		return
 	}
 
+	if next != 0 {
+		c.Header(NextCursorHeader, encodeCursor(next))
+	}
 	c.JSON(http.StatusOK, customers)
 }
 
-// Enter marks a new customer entrying the studio
+type enterForm struct {
+	Code string `form:"code" binding:"required"`
+}
+
+// Enter validates the entry ticket and marks the customer entrying the studio
 func (r Customers) Enter(c *gin.Context) {
-	// We can mark each entry of a customer with a random id
-	customer, err := r.DAO.Enter()
+	var input enterForm
+	err := c.Bind(&input)
 	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
-		log.Println(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	customer, reEntered, token, err := r.enter(input.Code)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "added", "customer_id": customer.ID})
+	response := gin.H{"status": "added", "customer_id": customer.ID}
+	if reEntered {
+		response["status"] = "re-entered"
+	}
+	if token != "" {
+		response["token"] = token
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 type exitForm struct {
@@ -54,9 +79,9 @@ func (r Customers) Exit(c *gin.Context) {
 		return
 	}
 
-	customer, err := r.DAO.Exit(input.ID)
+	customer, err := r.exit(input.ID)
 	if err != nil {
-		handleError(c, err, "customer")
+		abortWithFailure(c, err)
 		return
 	}
 
@@ -64,8 +89,9 @@ func (r Customers) Exit(c *gin.Context) {
 }
 
 type queueForm struct {
-	ID     uint `form:"id" binding:"required"`
-	RideID uint `form:"ride_id" binding:"required"`
+	ID        uint   `form:"id" binding:"required"`
+	RideID    uint   `form:"ride_id" binding:"required"`
+	QueueType string `form:"queue_type"`
 }
 
 // Queue marks a the customer entering a queue for a ride
@@ -76,22 +102,9 @@ func (r Customers) Queue(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
-
-	customer, err := r.DAO.Get(input.ID)
-	if err != nil {
-		handleError(c, err, "customer")
-		return
-	}
-
-	ride, err := r.RideDAO.Get(input.RideID)
-	if err != nil {
-		handleError(c, err, "ride")
-		return
-	}
-
-	err = customersEvents.LogCustomerInQueue(r.DAO.DB, customer, ride)
+	customer, err := r.queue(c.Request.Context(), input.ID, input.RideID, input.QueueType)
 	if err != nil {
-		handleError(c, err, "queue")
+		abortWithFailure(c, err)
 		return
 	}
 
@@ -110,18 +123,44 @@ func (r Customers) UnQueue(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
+	customer, err := r.unQueue(c.Request.Context(), input.ID)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "un-queued", "customer_id": customer.ID})
+}
 
-	customer, err := r.DAO.Get(input.ID)
+type recommendationsURI struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type recommendationsQuery struct {
+	Strategy string `form:"strategy"`
+}
+
+// Recommendations returns the rides a customer can go to next, ranked by a strategy
+func (r Customers) Recommendations(c *gin.Context) {
+	var uri recommendationsURI
+	err := c.ShouldBindUri(&uri)
 	if err != nil {
-		handleError(c, err, "customer")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
-	err = customersEvents.LogCustomerLeftAQueue(r.DAO.DB, customer)
+	var query recommendationsQuery
+	err = c.ShouldBindQuery(&query)
 	if err != nil {
-		handleError(c, err, "un-queue")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "un-queued", "customer_id": customer.ID})
+	recommended, err := r.recommend(c.Request.Context(), uri.ID, query.Strategy)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recommended)
 }
@@ -10,21 +10,29 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"gitlab.com/therako/universal-studios/api"
 	"gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/parties"
 	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
 	customerEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
 	"gotest.tools/v3/assert"
 )
 
 func TestCustomerEnter(t *testing.T) {
 	t.Run("expected to create a new customer on entry", func(t *testing.T) {
 		db := testDB(t.Name())
+		tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
 		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/customer/enter", nil)
+		req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, 200, w.Code)
@@ -35,6 +43,126 @@ func TestCustomerEnter(t *testing.T) {
 		assert.Assert(t, customer.Model.ID == 1)
 		assert.Assert(t, customer.Model.CreatedAt.Before(time.Now()))
 		assert.Assert(t, customer.ExitAt == nil)
+		assert.Equal(t, uint(1), *customer.TicketID)
+
+		ticket, _ := tickets.DAO{DB: db}.GetByCode("abc")
+		assert.Equal(t, uint(1), ticket.ScanCount)
+	})
+
+	t.Run("expected to re-activate the customer when ticket allows re-entry", func(t *testing.T) {
+		db := testDB(t.Name())
+		tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour), ReEntry: tickets.SameDayReEntry})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
+
+		enter := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		w := enter()
+		assert.Equal(t, 200, w.Code)
+
+		w = enter()
+		assert.Equal(t, 409, w.Code)
+		assert.Equal(t, `{"err":"ticket Ticket is in use by a customer inside the studio"}`, w.Body.String())
+
+		customers.DAO{DB: db}.Exit(1)
+		w = enter()
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"customer_id":1,"status":"re-entered"}`, w.Body.String())
+		customer, _ := customers.DAO{DB: db}.Get(1)
+		assert.Assert(t, customer.ExitAt == nil)
+	})
+
+	t.Run("error on re-entry when ticket doesn't allow it", func(t *testing.T) {
+		db := testDB(t.Name())
+		ticket := &tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)}
+		tickets.DAO{DB: db}.Add(ticket)
+		customer, _, err := customers.DAO{DB: db}.Enter(ticket.Code)
+		assert.NilError(t, err)
+		customers.DAO{DB: db}.Exit(customer.ID)
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 403, w.Code)
+		assert.Equal(t, `{"err":"ticket Ticket doesn't allow re-entry"}`, w.Body.String())
+	})
+
+	t.Run("expected the first entry of each day on multi day tickets", func(t *testing.T) {
+		db := testDB(t.Name())
+		day1 := time.Date(2021, 6, 1, 10, 0, 0, 0, time.Local)
+		ticket := &tickets.Ticket{Code: "abc", Type: "multi_day", ValidFrom: day1.Add(-10 * time.Hour), ValidTill: day1.AddDate(0, 0, 3)}
+		tickets.DAO{DB: db}.Add(ticket)
+		fakeClock := clockwork.NewFakeClockAt(day1)
+		dao := customers.DAO{DB: db, Clock: fakeClock}
+
+		customer, _, err := dao.Enter("abc")
+		assert.NilError(t, err)
+		dao.Exit(customer.ID)
+		fakeClock.Advance(time.Hour)
+		_, _, err = dao.Enter("abc")
+		assert.Equal(t, tickets.ErrTicketReEntryNotAllowed, err)
+
+		fakeClock.Advance(24 * time.Hour)
+		_, reEntered, err := dao.Enter("abc")
+		assert.NilError(t, err)
+		assert.Equal(t, true, reEntered)
+		ticket, _ = tickets.DAO{DB: db}.GetByCode("abc")
+		assert.Equal(t, uint(2), ticket.ScanCount)
+	})
+
+	t.Run("error on scanning a ticket scanned since it was read", func(t *testing.T) {
+		db := testDB(t.Name())
+		tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
+		read1, _ := tickets.DAO{DB: db}.GetByCode("abc")
+		read2, _ := tickets.DAO{DB: db}.GetByCode("abc")
+
+		assert.NilError(t, tickets.DAO{DB: db}.Scan(read1, time.Now()))
+		assert.Equal(t, tickets.ErrTicketScanConflict, tickets.DAO{DB: db}.Scan(read2, time.Now()))
+		ticket, _ := tickets.DAO{DB: db}.GetByCode("abc")
+		assert.Equal(t, uint(1), ticket.ScanCount)
+	})
+
+	t.Run("error on expired ticket", func(t *testing.T) {
+		db := testDB(t.Name())
+		tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "abc", ValidFrom: time.Now().Add(-48 * time.Hour), ValidTill: time.Now().Add(-24 * time.Hour)})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 410, w.Code)
+		assert.Equal(t, `{"err":"ticket Ticket has expired"}`, w.Body.String())
+	})
+
+	t.Run("error when ticket not found", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/enter", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"err":"ticket record not found"}`, w.Body.String())
 	})
 }
 
@@ -137,7 +265,7 @@ func TestCustomerQueued(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		assert.Equal(t, `{"customer_id":1,"status":"queued"}`, w.Body.String())
 
-		state, err := customerEvents.GetCurrentState(db, customer)
+		state, err := customerEvents.GetCurrentState(context.Background(), db, customer)
 		assert.NilError(t, err)
 		assert.Equal(t, true, state.Queueing)
 		assert.Equal(t, uint(1), state.RideID)
@@ -178,7 +306,7 @@ func TestCustomerUnQueued(t *testing.T) {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, 500, w.Code)
+		assert.Equal(t, 409, w.Code)
 		assert.Equal(t, `{"err":"un-queue Customer is not in any queue"}`, w.Body.String())
 	})
 
@@ -188,7 +316,7 @@ func TestCustomerUnQueued(t *testing.T) {
 		db.Create(customer)
 		ride := &rides.Ride{Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute}
 		db.Create(ride)
-		customerEvents.LogCustomerInQueue(db, customer, ride)
+		customerEvents.LogCustomerInQueue(context.Background(), db, customer, ride)
 		router := api.New(context.Background(), testConfig, db)
 		form := url.Values{}
 		form.Add("id", "123")
@@ -201,9 +329,186 @@ func TestCustomerUnQueued(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		assert.Equal(t, `{"customer_id":123,"status":"un-queued"}`, w.Body.String())
 
-		state, err := customerEvents.GetCurrentState(db, customer)
+		state, err := customerEvents.GetCurrentState(context.Background(), db, customer)
 		assert.NilError(t, err)
 		assert.Equal(t, false, state.Queueing)
 		assert.Equal(t, uint(0), state.RideID)
 	})
 }
+
+func TestCustomerRecommendations(t *testing.T) {
+	t.Run("expected to rank rides by wait excluding rides which are down", func(t *testing.T) {
+		db := testDB(t.Name())
+		customer := &customers.Customer{Model: models.Model{ID: 130}}
+		db.Create(customer)
+		allRides := []*rides.Ride{
+			{Model: models.Model{ID: 1301}, Name: "Busy", Capacity: 1, RideTime: 10 * time.Minute},
+			{Model: models.Model{ID: 1302}, Name: "Free", Capacity: 10, RideTime: 10 * time.Minute},
+			{Model: models.Model{ID: 1303}, Name: "Broken", Capacity: 10, RideTime: 10 * time.Minute},
+		}
+		db.Create(&allRides)
+		ridesEvents.LogCustomerJoinedRideQueue(context.Background(), db, allRides[0], &customers.Customer{Model: models.Model{ID: 131}})
+		ridesEvents.LogRideStatus(context.Background(), db, allRides[2], true)
+		router := api.New(context.Background(), testConfig, db)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/customer/130/recommendations", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		var recommended []*rides.Ride
+		err := json.Unmarshal(w.Body.Bytes(), &recommended)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(recommended))
+		assert.Equal(t, "Free", recommended[0].Name)
+		assert.Equal(t, "Busy", recommended[1].Name)
+	})
+
+	t.Run("error on unknown strategy", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&customers.Customer{})
+		router := api.New(context.Background(), testConfig, db)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/customer/1/recommendations?strategy=random", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code)
+		assert.Equal(t, `{"err":"Unknown strategy random"}`, w.Body.String())
+	})
+
+	t.Run("error when customer not found", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/customer/1/recommendations", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"err":"customer record not found"}`, w.Body.String())
+	})
+}
+
+func TestPartyEndpoints(t *testing.T) {
+	t.Run("expected to create a party and queue all members", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&[]*customers.Customer{{Model: models.Model{ID: 160}}, {Model: models.Model{ID: 161}}})
+		db.Create(&rides.Ride{Model: models.Model{ID: 1601}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("name", "family")
+		form.Add("member_ids", "160")
+		form.Add("member_ids", "161")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/party/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"party_id":1,"size":2,"status":"added"}`, w.Body.String())
+
+		form = url.Values{}
+		form.Add("id", "1")
+		form.Add("ride_id", "1601")
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/party/queue", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"party_id":1,"size":2,"status":"queued"}`, w.Body.String())
+		rideState, err := ridesEvents.GetCurrentState(context.Background(), db, &rides.Ride{Model: models.Model{ID: 1601}})
+		assert.NilError(t, err)
+		assert.Equal(t, uint(2), rideState.QueueCount)
+	})
+
+	t.Run("error when a member is not found", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&customers.Customer{Model: models.Model{ID: 162}})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("member_ids", "162")
+		form.Add("member_ids", "163")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/party/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"err":"customer record not found"}`, w.Body.String())
+	})
+
+	t.Run("expected to return party with members", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&customers.Customer{Model: models.Model{ID: 164}})
+		parties.DAO{DB: db}.Create("couple", []uint{164})
+		router := api.New(context.Background(), testConfig, db)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/party/1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		party := &parties.Party{}
+		err := json.Unmarshal(w.Body.Bytes(), party)
+		assert.NilError(t, err)
+		assert.Equal(t, "couple", party.Name)
+		assert.Equal(t, 1, len(party.Members))
+		assert.Equal(t, uint(164), party.Members[0].ID)
+	})
+}
+
+func TestCustomerQueuedByQueueType(t *testing.T) {
+	t.Run("expected to log customer in the single rider queue", func(t *testing.T) {
+		db := testDB(t.Name())
+		customer := &customers.Customer{Model: models.Model{ID: 170}}
+		db.Create(customer)
+		db.Create(&rides.Ride{Model: models.Model{ID: 1701}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider"}})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "170")
+		form.Add("ride_id", "1701")
+		form.Add("queue_type", "single_rider")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/queue", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		state, err := customerEvents.GetCurrentState(context.Background(), db, customer)
+		assert.NilError(t, err)
+		assert.Equal(t, ridesEvents.SingleRider, state.QueueType)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/ride", nil)
+		router.ServeHTTP(w, req)
+		var responseRides []*rides.Ride
+		err = json.Unmarshal(w.Body.Bytes(), &responseRides)
+		assert.NilError(t, err)
+		assert.Equal(t, uint(0), responseRides[0].InQueue)
+		assert.Equal(t, uint(1), responseRides[0].QueueInCounts["single_rider"])
+	})
+
+	t.Run("error when ride doesn't support the queue type", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&customers.Customer{Model: models.Model{ID: 171}})
+		db.Create(&rides.Ride{Model: models.Model{ID: 1702}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "171")
+		form.Add("ride_id", "1702")
+		form.Add("queue_type", "virtual")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/customer/queue", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 422, w.Code)
+		assert.Equal(t, `{"err":"queue Ride doesn't support this queue type"}`, w.Body.String())
+	})
+}
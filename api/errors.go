@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/therako/universal-studios/domain"
+	"gitlab.com/therako/universal-studios/logging"
+	"gorm.io/gorm"
+)
+
+// Error codes returned in the v1 error envelope, domain errors are returned with their own codes
+const (
+	CodeInvalidRequest  = "invalid_request"
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeInternal        = "internal"
+)
+
+// ErrorResponse is the envelope all v1 errors are returned in
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes what went wrong, code is stable for clients to act on
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// abortWithError responds the error as an ErrorResponse on v1 routes & as `{"err": ...}` on the rest
+func abortWithError(c *gin.Context, status int, code string, message string) {
+	if strings.HasPrefix(c.FullPath(), v1Prefix+"/") {
+		c.AbortWithStatusJSON(status, ErrorResponse{Error: ErrorBody{Code: code, Message: message}})
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{"err": message})
+}
+
+// httpStatuses maps each kind of domain error to the status it's responded with
+var httpStatuses = map[domain.Kind]int{
+	domain.Invalid:       http.StatusBadRequest,
+	domain.NotFound:      http.StatusNotFound,
+	domain.Conflict:      http.StatusConflict,
+	domain.Gone:          http.StatusGone,
+	domain.Forbidden:     http.StatusForbidden,
+	domain.Unprocessable: http.StatusUnprocessableEntity,
+}
+
+// classify returns the status & code for an error, unexpected errors are internal
+func classify(err error) (int, string) {
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &domainErr):
+		status, ok := httpStatuses[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return status, domainErr.Code
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, CodeNotFound
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
+
+func handleError(c *gin.Context, err error, errPrefix string) {
+	abortWithFailure(c, fail(errPrefix, err))
+}
+
+// abortWithFailure responds an error returned by an operation, unexpected errors are logged
+func abortWithFailure(c *gin.Context, err error) {
+	status, code := classify(err)
+	if status == http.StatusInternalServerError {
+		logging.FromContext(c.Request.Context()).Error("Request failed", "err", err)
+	}
+	abortWithError(c, status, code, err.Error())
+}
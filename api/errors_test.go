@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/api/pb"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/parties"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gotest.tools/v3/assert"
+)
+
+func TestDomainErrors(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3701}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3702}, Name: "ride2", Capacity: 10, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider"}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 370}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 371}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 372}, ExitAt: models.TimeP(time.Now())})
+	db.Create(&parties.Party{Model: models.Model{ID: 370}, Name: "empty"})
+	db.Create(&customers.Customer{Model: models.Model{ID: 373}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 374}})
+	party, err := parties.DAO{DB: db}.Create("large", []uint{373, 374})
+	assert.NilError(t, err)
+
+	ticketDAO := tickets.DAO{DB: db}
+	today := time.Now()
+	ticketDAO.Add(&tickets.Ticket{Code: "early", ValidFrom: today.Add(24 * time.Hour), ValidTill: today.Add(48 * time.Hour)})
+	ticketDAO.Add(&tickets.Ticket{Code: "late", ValidFrom: today.Add(-48 * time.Hour), ValidTill: today.Add(-24 * time.Hour)})
+	ticketDAO.Add(&tickets.Ticket{Code: "in-use", ValidFrom: today.Add(-time.Hour), ValidTill: today.Add(time.Hour), ReEntry: tickets.UnlimitedReEntry})
+	ticketDAO.Add(&tickets.Ticket{Code: "once", ValidFrom: today.Add(-time.Hour), ValidTill: today.Add(time.Hour)})
+
+	router := api.New(context.Background(), testConfig, db)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/370/queue", `{"ride_id":3701}`).Code)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers", `{"code":"in-use"}`).Code)
+	enter := serveJSON(router, "POST", "/v1/customers", `{"code":"once"}`)
+	assert.Equal(t, 200, enter.Code)
+	entered := api.EnterResponse{}
+	assert.NilError(t, json.Unmarshal(enter.Body.Bytes(), &entered))
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/"+jsonNumber(entered.CustomerID)+"/exit", ``).Code)
+
+	testCases := []struct {
+		desc   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"customer already in a queue", "POST", "/v1/customers/370/queue", `{"ride_id":3701}`, 409, "customer_already_queued"},
+		{"customer not in a queue", "POST", "/v1/customers/371/unqueue", ``, 409, "customer_not_queued"},
+		{"exited customer queueing", "POST", "/v1/customers/372/queue", `{"ride_id":3701}`, 410, "customer_exited"},
+		{"exited customer un-queueing", "POST", "/v1/customers/372/unqueue", ``, 410, "customer_exited"},
+		{"unknown queue type", "POST", "/v1/customers/371/queue", `{"ride_id":3701,"queue_type":"express"}`, 400, "unknown_queue_type"},
+		{"queue type the ride doesn't have", "POST", "/v1/customers/371/queue", `{"ride_id":3701,"queue_type":"single_rider"}`, 422, "queue_type_not_supported"},
+		{"party larger than the ride", "POST", "/v1/parties/" + jsonNumber(party.ID) + "/queue", `{"ride_id":3701}`, 422, "party_too_large"},
+		{"party without members", "POST", "/v1/parties/370/queue", `{"ride_id":3702}`, 400, "party_has_no_members"},
+		{"adding a party without members", "POST", "/v1/parties", `{"name":"nobody","member_ids":[]}`, 400, "party_has_no_members"},
+		{"adding a member of another party", "POST", "/v1/parties", `{"name":"split","member_ids":[371,373]}`, 409, "member_in_another_party"},
+		{"ticket used by a customer inside", "POST", "/v1/customers", `{"code":"in-use"}`, 409, "ticket_in_use"},
+		{"ticket not valid yet", "POST", "/v1/customers", `{"code":"early"}`, 403, "ticket_not_valid_yet"},
+		{"expired ticket", "POST", "/v1/customers", `{"code":"late"}`, 410, "ticket_expired"},
+		{"ticket without re-entry", "POST", "/v1/customers", `{"code":"once"}`, 403, "ticket_re_entry_not_allowed"},
+		{"unknown re-entry policy", "POST", "/v1/tickets", `{"re_entry":"sometimes"}`, 400, "unknown_re_entry_policy"},
+		{"unknown ride", "POST", "/v1/customers/371/queue", `{"ride_id":404}`, 404, api.CodeNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			w := serveJSON(router, tC.method, tC.path, tC.body)
+
+			assert.Equal(t, tC.status, w.Code)
+			response := api.ErrorResponse{}
+			assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tC.code, response.Error.Code)
+		})
+	}
+
+	t.Run("legacy routes respond with the same status", func(t *testing.T) {
+		w := postForm(router, "/customer/queue", url.Values{"id": {"370"}, "ride_id": {"3701"}})
+
+		assert.Equal(t, 409, w.Code)
+		assert.Equal(t, `{"err":"queue Customer is already in a queue or riding"}`, w.Body.String())
+	})
+
+	t.Run("gRPC responds with a matching code", func(t *testing.T) {
+		client := pb.NewCustomerServiceClient(testGRPC(t, testConfig, db))
+
+		_, err := client.Queue(context.Background(), &pb.QueueRequest{CustomerId: 370, RideId: 3701})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		_, err = client.Queue(context.Background(), &pb.QueueRequest{CustomerId: 371, RideId: 3701, QueueType: "express"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,445 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"gitlab.com/therako/universal-studios/api/pb"
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/domain"
+	"gitlab.com/therako/universal-studios/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// watchInterval is how often a watched ride is re-checked, catching changes made by other
+// instances of the service & the waiting time running down
+var watchInterval = 5 * time.Second
+
+// grpcRequestIDKey is the metadata key carrying the call's request ID
+const grpcRequestIDKey = "x-request-id"
+
+// grpcRoles are the roles allowed to call each gRPC method, admin is always allowed
+var grpcRoles = map[string][]Role{
+	"/studios.v1.RideService/ListRides":      {RoleOperator, RoleGate, RoleGuest},
+	"/studios.v1.RideService/AddRide":        {RoleAdmin},
+	"/studios.v1.RideService/SetRideStatus":  {RoleOperator},
+	"/studios.v1.RideService/GetRideState":   {RoleOperator, RoleGate, RoleGuest},
+	"/studios.v1.RideService/WatchRideState": {RoleOperator, RoleGate, RoleGuest},
+
+	"/studios.v1.CustomerService/ListCustomers":    {RoleOperator, RoleGate},
+	"/studios.v1.CustomerService/Enter":            {RoleGate},
+	"/studios.v1.CustomerService/Exit":             {RoleGate},
+	"/studios.v1.CustomerService/Queue":            {RoleOperator, RoleGuest},
+	"/studios.v1.CustomerService/UnQueue":          {RoleOperator, RoleGuest},
+	"/studios.v1.CustomerService/GetCustomerState": {RoleOperator, RoleGate, RoleGuest},
+}
+
+// NewGRPCServer Returns a gRPC server with the ride & customer services, sharing the DAOs &
+// events with the HTTP router
+func NewGRPCServer(ctx context.Context, config Config, gormDB *gorm.DB) *grpc.Server {
+	return newGRPCServer(ctx, config, gormDB, NewServices(config, gormDB))
+}
+
+// newGRPCServer returns the gRPC server on the given services, shared with the HTTP router
+func newGRPCServer(ctx context.Context, config Config, gormDB *gorm.DB, services Services) *grpc.Server {
+	auth := newAuthenticator(config, services.Rides.Clock)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.unaryInterceptor),
+		grpc.StreamInterceptor(auth.streamInterceptor),
+	)
+
+	ops := newOperations(gormDB, services, auth)
+	pb.RegisterRideServiceServer(server, &rideServer{operations: ops})
+	pb.RegisterCustomerServiceServer(server, &customerServer{operations: ops})
+	return server
+}
+
+// authorizeCall authenticates the caller from the `x-api-key` or `authorization` metadata
+func (a *authenticator) authorizeCall(ctx context.Context, method string) (context.Context, error) {
+	if !a.enabled() {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, err := a.authenticateCredentials(firstValue(md, "x-api-key"), firstValue(md, "authorization"))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !p.allows(grpcRoles[method]) {
+		return nil, status.Error(codes.PermissionDenied, ErrForbidden.Error())
+	}
+	return context.WithValue(ctx, principalContextKey{}, p), nil
+}
+
+// callContext sets a logger carrying the call's request ID on ctx, taken from the `x-request-id`
+// metadata when given, & returns the ID to be sent back in the header
+func callContext(ctx context.Context, method string) (context.Context, metadata.MD) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := firstValue(md, grpcRequestIDKey)
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	ctx = logging.With(ctx, logging.RequestIDKey, requestID, "method", method)
+	return ctx, metadata.Pairs(grpcRequestIDKey, requestID)
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, header := callContext(ctx, info.FullMethod)
+	_ = grpc.SetHeader(ctx, header)
+
+	ctx, err := a.authorizeCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if err != nil && status.Code(err) == codes.Internal {
+		logging.FromContext(ctx).Error("Call failed", "err", err)
+	}
+	return resp, err
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, header := callContext(stream.Context(), info.FullMethod)
+	_ = stream.SetHeader(header)
+
+	ctx, err := a.authorizeCall(ctx, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorizedStream carries the principal & logger in the stream's context
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcCodes maps each kind of domain error to the gRPC code it's returned with
+var grpcCodes = map[domain.Kind]codes.Code{
+	domain.Invalid:       codes.InvalidArgument,
+	domain.NotFound:      codes.NotFound,
+	domain.Conflict:      codes.FailedPrecondition,
+	domain.Gone:          codes.FailedPrecondition,
+	domain.Forbidden:     codes.PermissionDenied,
+	domain.Unprocessable: codes.FailedPrecondition,
+}
+
+func grpcError(err error, errPrefix string) error {
+	return grpcFailure(fail(errPrefix, err))
+}
+
+// grpcFailure returns an error returned by an operation with the status code of it's kind
+func grpcFailure(err error) error {
+	code := codes.Internal
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &domainErr):
+		if c, ok := grpcCodes[domainErr.Kind]; ok {
+			code = c
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		code = codes.NotFound
+	}
+	return status.Error(code, err.Error())
+}
+
+type rideServer struct {
+	pb.UnimplementedRideServiceServer
+	operations
+}
+
+// ListRides returns rides matching the filters along with their current state
+func (s *rideServer) ListRides(ctx context.Context, req *pb.ListRidesRequest) (*pb.ListRidesResponse, error) {
+	sort, desc := rideSort(req.Sort)
+	filter := rides.Filter{
+		Zone:           req.Zone,
+		Tag:            req.Tag,
+		Accessibility:  req.Accessibility,
+		MaxThrillLevel: uint(req.MaxThrillLevel),
+		HeightCm:       uint(req.HeightCm),
+		Age:            uint(req.Age),
+		Sort:           sort,
+		Desc:           desc,
+	}
+	if req.MaxWaitSecs != nil {
+		maxWait := time.Duration(*req.MaxWaitSecs) * time.Second
+		filter.MaxWait = &maxWait
+	}
+	allRides, err := s.Services.Rides.List(ctx, filter)
+	if err != nil {
+		return nil, grpcError(err, "rides")
+	}
+
+	response := &pb.ListRidesResponse{}
+	for _, ride := range allRides {
+		state, err := s.currentState(ctx, ride)
+		if err != nil {
+			return nil, err
+		}
+		message := rideMessage(ride)
+		message.State = state
+		response.Rides = append(response.Rides, message)
+	}
+	return response, nil
+}
+
+// AddRide adds a new ride to the studio
+func (s *rideServer) AddRide(ctx context.Context, req *pb.AddRideRequest) (*pb.Ride, error) {
+	ride, err := s.addRide(AddRideRequest{
+		Name:               req.Name,
+		Desc:               req.Desc,
+		RideTimeSecs:       uint(req.RideTimeSecs),
+		Capacity:           uint(req.Capacity),
+		Zone:               req.Zone,
+		MinHeightCm:        uint(req.MinHeightCm),
+		MinAge:             uint(req.MinAge),
+		ThrillLevel:        uint(req.ThrillLevel),
+		Accessibility:      req.Accessibility,
+		Tags:               req.Tags,
+		QueueTypes:         req.QueueTypes,
+		PriorityMergeRatio: uint(req.PriorityMergeRatio),
+	})
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return rideMessage(ride), nil
+}
+
+// SetRideStatus marks a ride as down or back up
+func (s *rideServer) SetRideStatus(ctx context.Context, req *pb.SetRideStatusRequest) (*pb.RideState, error) {
+	ride, err := s.setRideStatus(ctx, uint(req.RideId), req.Down)
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return s.currentState(ctx, ride)
+}
+
+// GetRideState returns the current queue state of a ride
+func (s *rideServer) GetRideState(ctx context.Context, req *pb.GetRideStateRequest) (*pb.RideState, error) {
+	ride, err := s.RideDAO.Get(uint(req.RideId))
+	if err != nil {
+		return nil, grpcError(err, "ride")
+	}
+	return s.currentState(ctx, ride)
+}
+
+// WatchRideState sends the current state of a ride and then every change to it, till the client leaves
+func (s *rideServer) WatchRideState(req *pb.WatchRideStateRequest, stream pb.RideService_WatchRideStateServer) error {
+	ride, err := s.RideDAO.Get(uint(req.RideId))
+	if err != nil {
+		return grpcError(err, "ride")
+	}
+
+	changed, stop := s.Services.Rides.Bus.Watch(ride.ID)
+	defer stop()
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var last *pb.RideState
+	for {
+		state, err := s.currentState(stream.Context(), ride)
+		if err != nil {
+			return err
+		}
+		if last == nil || !sameRideState(last, state) {
+			err = stream.Send(state)
+			if err != nil {
+				return err
+			}
+			last = state
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *rideServer) currentState(ctx context.Context, ride *rides.Ride) (*pb.RideState, error) {
+	state, err := s.Services.Rides.GetCurrentState(ctx, ride)
+	if err != nil {
+		return nil, grpcError(err, "ride")
+	}
+	state.Apply(ride, clock.Or(s.Clock).Now())
+
+	message := &pb.RideState{
+		RideId: uint32(ride.ID),
+		Down:   state.Down,
+		Riders: uint32(state.Riders),
+		Standby: &pb.QueueState{
+			InQueue:         uint32(ride.InQueue),
+			WaitingTimeSecs: uint32(ride.EstimatedWaitingTime.Seconds()),
+		},
+		Queues:    map[string]*pb.QueueState{},
+		UpdatedAt: timestamppb.New(state.UpdatedAt),
+	}
+	for queueType, wait := range ride.QueueWaitingTimes {
+		message.Queues[queueType] = &pb.QueueState{
+			InQueue:         uint32(ride.QueueInCounts[queueType]),
+			WaitingTimeSecs: uint32(wait.Seconds()),
+		}
+	}
+	return message, nil
+}
+
+// sameRideState compares states leaving out when they were calculated
+func sameRideState(a, b *pb.RideState) bool {
+	a = proto.Clone(a).(*pb.RideState)
+	b = proto.Clone(b).(*pb.RideState)
+	a.UpdatedAt, b.UpdatedAt = nil, nil
+	return proto.Equal(a, b)
+}
+
+func rideMessage(ride *rides.Ride) *pb.Ride {
+	return &pb.Ride{
+		Id:                 uint32(ride.ID),
+		Name:               ride.Name,
+		Desc:               ride.Desc,
+		RideTimeSecs:       uint32(ride.RideTime.Seconds()),
+		Capacity:           uint32(ride.Capacity),
+		Zone:               ride.Zone,
+		MinHeightCm:        uint32(ride.MinHeightCm),
+		MinAge:             uint32(ride.MinAge),
+		ThrillLevel:        uint32(ride.ThrillLevel),
+		Accessibility:      ride.Accessibility,
+		Tags:               ride.Tags,
+		QueueTypes:         ride.QueueTypes,
+		PriorityMergeRatio: uint32(ride.PriorityMergeRatio),
+	}
+}
+
+type customerServer struct {
+	pb.UnimplementedCustomerServiceServer
+	operations
+}
+
+// ListCustomers returns a page of customers, by default the ones inside the studio
+func (s *customerServer) ListCustomers(ctx context.Context, req *pb.ListCustomersRequest) (*pb.ListCustomersResponse, error) {
+	after, err := decodeCursor(req.PageToken)
+	if err != nil {
+		return nil, grpcError(err, "customers")
+	}
+	filter := customers.Filter{
+		Status:   customers.Status(req.Status),
+		Queueing: req.Queueing,
+		After:    after,
+		PageSize: int(req.PageSize),
+	}
+	if req.CreatedFrom != nil {
+		filter.CreatedFrom = models.TimeP(req.CreatedFrom.AsTime())
+	}
+	if req.CreatedTill != nil {
+		filter.CreatedTill = models.TimeP(req.CreatedTill.AsTime())
+	}
+
+	allCustomers, next, err := s.CustomerDAO.List(filter)
+	if err != nil {
+		return nil, grpcError(err, "customers")
+	}
+
+	response := &pb.ListCustomersResponse{NextPageToken: encodeCursor(next)}
+	for _, customer := range allCustomers {
+		response.Customers = append(response.Customers, customerMessage(customer))
+	}
+	return response, nil
+}
+
+// Enter validates the entry ticket and lets the customer in
+func (s *customerServer) Enter(ctx context.Context, req *pb.EnterRequest) (*pb.EnterResponse, error) {
+	if req.TicketCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "ticket_code is required")
+	}
+
+	customer, reEntered, token, err := s.enter(req.TicketCode)
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return &pb.EnterResponse{Customer: customerMessage(customer), ReEntered: reEntered, Token: token}, nil
+}
+
+// Exit marks the customer leaving the studio
+func (s *customerServer) Exit(ctx context.Context, req *pb.ExitRequest) (*pb.Customer, error) {
+	customer, err := s.exit(uint(req.CustomerId))
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return customerMessage(customer), nil
+}
+
+// Queue adds the customer to a queue of a ride
+func (s *customerServer) Queue(ctx context.Context, req *pb.QueueRequest) (*pb.CustomerState, error) {
+	_, err := s.queue(ctx, uint(req.CustomerId), uint(req.RideId), req.QueueType)
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return s.currentState(ctx, uint(req.CustomerId))
+}
+
+// UnQueue takes the customer out of their queue
+func (s *customerServer) UnQueue(ctx context.Context, req *pb.UnQueueRequest) (*pb.CustomerState, error) {
+	_, err := s.unQueue(ctx, uint(req.CustomerId))
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+	return s.currentState(ctx, uint(req.CustomerId))
+}
+
+// GetCustomerState returns the current queue state of a customer
+func (s *customerServer) GetCustomerState(ctx context.Context, req *pb.GetCustomerStateRequest) (*pb.CustomerState, error) {
+	return s.currentState(ctx, uint(req.CustomerId))
+}
+
+func (s *customerServer) currentState(ctx context.Context, id uint) (*pb.CustomerState, error) {
+	state, err := s.customerState(ctx, id)
+	if err != nil {
+		return nil, grpcFailure(err)
+	}
+
+	return &pb.CustomerState{
+		CustomerId: uint32(id),
+		Queueing:   state.Queueing,
+		RideId:     uint32(state.RideID),
+		QueueType:  string(state.QueueType),
+		From:       timestamppb.New(state.From),
+		To:         timestamppb.New(state.To),
+	}, nil
+}
+
+func customerMessage(customer *customers.Customer) *pb.Customer {
+	message := &pb.Customer{
+		Id:        uint32(customer.ID),
+		EnteredAt: timestamppb.New(customer.CreatedAt),
+	}
+	if customer.ExitAt != nil {
+		message.ExitAt = timestamppb.New(*customer.ExitAt)
+	}
+	if customer.PartyID != nil {
+		message.PartyId = uint32(*customer.PartyID)
+	}
+	return message
+}
@@ -0,0 +1,172 @@
+package api_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/api/pb"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+// testGRPC serves the gRPC API over an in memory connection & returns a client connection to it
+func testGRPC(t *testing.T, config api.Config, db *gorm.DB) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := api.NewGRPCServer(context.Background(), config, db)
+	go server.Serve(listener)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+	return conn
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestGRPCRides(t *testing.T) {
+	db := testDB(t.Name())
+	client := pb.NewRideServiceClient(testGRPC(t, testConfig, db))
+	ctx := context.Background()
+
+	ride, err := client.AddRide(ctx, &pb.AddRideRequest{Name: "DareDevil", RideTimeSecs: 300, Capacity: 20, Zone: "Jurassic"})
+	assert.NilError(t, err)
+	assert.Equal(t, "DareDevil", ride.Name)
+	stored, err := rides.DAO{DB: db}.Get(uint(ride.Id))
+	assert.NilError(t, err)
+	assert.Equal(t, 5*time.Minute, stored.RideTime)
+
+	_, err = client.AddRide(ctx, &pb.AddRideRequest{Name: "NoCapacity", RideTimeSecs: 300})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	db.Create(&rides.Ride{Model: models.Model{ID: 3501}, Name: "Carousel", Capacity: 10, RideTime: 5 * time.Minute, Zone: "Egypt"})
+	list, err := client.ListRides(ctx, &pb.ListRidesRequest{Zone: "Egypt"})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(list.Rides))
+	assert.Equal(t, uint32(3501), list.Rides[0].Id)
+	assert.Equal(t, false, list.Rides[0].State.Down)
+
+	state, err := client.SetRideStatus(ctx, &pb.SetRideStatusRequest{RideId: 3501, Down: true})
+	assert.NilError(t, err)
+	assert.Equal(t, true, state.Down)
+
+	_, err = client.GetRideState(ctx, &pb.GetRideStateRequest{RideId: 404})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCCustomerQueue(t *testing.T) {
+	db := testDB(t.Name())
+	// Entered customer gets the next id, keeping it apart from customers cached by other tests
+	db.Create(&customers.Customer{Model: models.Model{ID: 349}})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3502}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "grpc-abc", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
+	client := pb.NewCustomerServiceClient(testGRPC(t, testConfig, db))
+	ctx := context.Background()
+
+	entered, err := client.Enter(ctx, &pb.EnterRequest{TicketCode: "grpc-abc"})
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(350), entered.Customer.Id)
+
+	state, err := client.Queue(ctx, &pb.QueueRequest{CustomerId: 350, RideId: 3502})
+	assert.NilError(t, err)
+	assert.Equal(t, true, state.Queueing)
+	assert.Equal(t, uint32(3502), state.RideId)
+	assert.Equal(t, "standby", state.QueueType)
+	assert.Assert(t, state.To.AsTime().Sub(state.From.AsTime()) > 19*time.Minute)
+
+	_, err = client.Queue(ctx, &pb.QueueRequest{CustomerId: 350, RideId: 404})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	state, err = client.UnQueue(ctx, &pb.UnQueueRequest{CustomerId: 350})
+	assert.NilError(t, err)
+	assert.Equal(t, false, state.Queueing)
+
+	customer, err := client.Exit(ctx, &pb.ExitRequest{CustomerId: 350})
+	assert.NilError(t, err)
+	assert.Assert(t, customer.ExitAt != nil)
+}
+
+func TestGRPCWatchRideState(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&customers.Customer{Model: models.Model{ID: 351}})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3503}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	conn := testGRPC(t, testConfig, db)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewRideServiceClient(conn).WatchRideState(ctx, &pb.WatchRideStateRequest{RideId: 3503})
+	assert.NilError(t, err)
+	state, err := stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(0), state.Standby.InQueue)
+
+	_, err = pb.NewCustomerServiceClient(conn).Queue(ctx, &pb.QueueRequest{CustomerId: 351, RideId: 3503})
+	assert.NilError(t, err)
+
+	state, err = stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(1), state.Standby.InQueue)
+	assert.Equal(t, uint32(1), state.Riders)
+	assert.Assert(t, state.Standby.WaitingTimeSecs > 590)
+
+	_, err = pb.NewRideServiceClient(conn).SetRideStatus(ctx, &pb.SetRideStatusRequest{RideId: 3503, Down: true})
+	assert.NilError(t, err)
+
+	state, err = stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, true, state.Down)
+}
+
+func TestGRPCAuth(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&customers.Customer{Model: models.Model{ID: 352}})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3504}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "grpc-auth", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
+	conn := testGRPC(t, authConfig, db)
+	rideClient := pb.NewRideServiceClient(conn)
+	customerClient := pb.NewCustomerServiceClient(conn)
+
+	_, err := rideClient.ListRides(context.Background(), &pb.ListRidesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = rideClient.AddRide(withKey("gate-key"), &pb.AddRideRequest{Name: "DareDevil", RideTimeSecs: 300, Capacity: 20})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = rideClient.ListRides(withKey("gate-key"), &pb.ListRidesRequest{})
+	assert.NilError(t, err)
+
+	stream, err := rideClient.WatchRideState(context.Background(), &pb.WatchRideStateRequest{RideId: 3504})
+	assert.NilError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	entered, err := customerClient.Enter(withKey("gate-key"), &pb.EnterRequest{TicketCode: "grpc-auth"})
+	assert.NilError(t, err)
+	assert.Assert(t, entered.Token != "")
+	guest := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+entered.Token)
+
+	_, err = customerClient.Queue(guest, &pb.QueueRequest{CustomerId: 352, RideId: 3504})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = customerClient.Queue(guest, &pb.QueueRequest{CustomerId: entered.Customer.Id, RideId: 3504})
+	assert.NilError(t, err)
+}
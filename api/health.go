@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds how long the DB is pinged for on each readiness check
+var readinessTimeout = 2 * time.Second
+
+// Health check statuses
+const (
+	StatusOK       = "ok"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+	// StatusDisabled checks are of dependencies turned off, eg. unsized caches, & don't fail readiness
+	StatusDisabled = "disabled"
+)
+
+// HealthResponse is returned by the liveness & readiness checks, checks has the status of each dependency
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// health serves the liveness & readiness checks, readiness fails once the server starts draining so
+// load balancers stop sending it new requests
+type health struct {
+	DB       *gorm.DB
+	Services Services
+	draining int32
+}
+
+func newHealth(gormDB *gorm.DB, services Services) *health {
+	return &health{DB: gormDB, Services: services}
+}
+
+// drain fails readiness checks from now on
+func (h *health) drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// live responds OK as long as the process is up & serving
+func (h *health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: StatusOK})
+}
+
+// ready checks the DB connection & state caches are usable
+func (h *health) ready(c *gin.Context) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: StatusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+	checks := map[string]string{
+		"db":             h.pingDB(ctx),
+		"customer_cache": cacheStatus(h.Services.Customers.Cache),
+		"ride_cache":     cacheStatus(h.Services.Rides.Cache),
+	}
+	for _, check := range checks {
+		if check != StatusOK && check != StatusDisabled {
+			c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: StatusNotReady, Checks: checks})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, HealthResponse{Status: StatusReady, Checks: checks})
+}
+
+func (h *health) pingDB(ctx context.Context) string {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return err.Error()
+	}
+	if err = sqlDB.PingContext(ctx); err != nil {
+		return err.Error()
+	}
+	return StatusOK
+}
+
+func cacheStatus(cache *ristretto.Cache) string {
+	if cache == nil {
+		return StatusDisabled
+	}
+	return StatusOK
+}
@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gotest.tools/v3/assert"
+)
+
+func TestHealthChecks(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), api.Config{}, db)
+
+	w := serveJSON(router, "GET", "/healthz", "")
+	assert.Equal(t, 200, w.Code)
+
+	w = serveJSON(router, "GET", "/readyz", "")
+	assert.Equal(t, 200, w.Code)
+	response := api.HealthResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.DeepEqual(t, api.HealthResponse{Status: api.StatusReady, Checks: map[string]string{
+		"db":             api.StatusOK,
+		"customer_cache": api.StatusDisabled,
+		"ride_cache":     api.StatusDisabled,
+	}}, response)
+
+	t.Run("expected sized caches to be checked", func(t *testing.T) {
+		config := api.Config{CustomerCacheNumCounters: 1000, CustomerCacheMaxCost: 1 << 20, RideCacheNumCounters: 1000, RideCacheMaxCost: 1 << 20}
+		w := serveJSON(api.New(context.Background(), config, db), "GET", "/readyz", "")
+		assert.Equal(t, 200, w.Code)
+		response := api.HealthResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, api.StatusOK, response.Checks["customer_cache"])
+		assert.Equal(t, api.StatusOK, response.Checks["ride_cache"])
+	})
+
+	t.Run("expected not to be ready without a DB connection", func(t *testing.T) {
+		sqlDB, err := db.DB()
+		assert.NilError(t, err)
+		sqlDB.Close()
+
+		w := serveJSON(router, "GET", "/readyz", "")
+		assert.Equal(t, 503, w.Code)
+		response := api.HealthResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, api.StatusNotReady, response.Status)
+		assert.Assert(t, response.Checks["db"] != api.StatusOK)
+
+		w = serveJSON(router, "GET", "/healthz", "")
+		assert.Equal(t, 200, w.Code, "expected to still be live")
+	})
+}
+
+func TestServerGracefulShutdown(t *testing.T) {
+	db := testDB(t.Name())
+	config := api.Config{IdempotencyWindowSecs: 60, ShutdownTimeoutSecs: 5, AuthDisabled: true}
+	server := api.NewServer(context.Background(), config, db)
+
+	// Holds requests to /slow till released, standing in for a slow queue write
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := server.HTTP.Handler
+	server.HTTP.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(entered)
+			<-release
+			r.URL.Path = "/healthz"
+		}
+		router.ServeHTTP(w, r)
+	})
+
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx, httpListener, grpcListener) }()
+
+	responded := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + httpListener.Addr().String() + "/slow")
+		if err != nil {
+			responded <- 0
+			return
+		}
+		resp.Body.Close()
+		responded <- resp.StatusCode
+	}()
+	<-entered
+	cancel()
+
+	select {
+	case err := <-served:
+		t.Fatalf("expected to wait for the in-flight request, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, 503, w.Code, "expected not to be ready while draining")
+
+	close(release)
+	assert.Equal(t, 200, <-responded)
+	select {
+	case err := <-served:
+		assert.NilError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected to shut down once the in-flight request finished")
+	}
+}
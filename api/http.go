@@ -2,43 +2,78 @@ package api
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gitlab.com/therako/universal-studios/data/customers"
-	"gitlab.com/therako/universal-studios/data/events"
-	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/logging"
 	"gorm.io/gorm"
 )
 
 // New Returns a HTTP router with all studios routes
 func New(ctx context.Context, config Config, gormDB *gorm.DB) *gin.Engine {
+	services := NewServices(config, gormDB)
+	return newRouter(ctx, config, gormDB, services, &workers{}, newHealth(gormDB, services))
+}
+
+// newRouter returns the router on the given services, running it's background workers on the given
+// workers so they can be waited on when shutting down
+func newRouter(ctx context.Context, config Config, gormDB *gorm.DB, services Services, w *workers, h *health) *gin.Engine {
 	router := gin.New()
-	router.Use(gin.Logger())
+	// Registered before the middlewares so probes aren't logged, traced or timed
+	router.GET("/healthz", h.live)
+	router.GET("/readyz", h.ready)
+
+	router.Use(traceRequests)
+	router.Use(requestLogger)
 	router.Use(gin.Recovery())
+	router.Use(observeLatency)
 
-	r := Rides{DAO: rides.DAO{DB: gormDB}}
-	router.GET("/ride", r.List)
-	router.POST("/ride/add", r.Add)
+	auth := newAuthenticator(config, services.Rides.Clock)
+	if !auth.enabled() {
+		logging.FromContext(ctx).Warn("Auth is disabled, all routes are open")
+	}
 
-	c := Customers{DAO: customers.DAO{DB: gormDB}, RideDAO: rides.DAO{DB: gormDB}, eventDAO: events.DAO{DB: gormDB}}
-	router.GET("/customer", c.List)
-	router.POST("/customer/enter", c.Enter)
-	router.POST("/customer/exit", c.Exit)
-	router.POST("/customer/queue", c.Queue)
-	router.POST("/customer/unqueue", c.UnQueue)
+	idempotent := newIdempotent(config, gormDB, services.Rides.Clock)
+	if idempotent.enabled() {
+		router.Use(idempotent.handle)
+		w.run(func() { idempotent.cleanup(ctx) })
+	}
 
+	registerV1(router, auth, gormDB, services)
+	if !config.MetricsDisabled {
+		// Public like the OpenAPI spec, meant to be scraped from within the studio's network
+		router.GET("/metrics", metricsHandler(services.Rides))
+	}
+	if !config.LegacyRoutesDisabled {
+		registerLegacy(router, auth, gormDB, services)
+	}
 	return router
 }
 
-func handleError(c *gin.Context, err error, errPrefix string) {
-	var status int
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		status = http.StatusNotFound
-	} else {
-		status = http.StatusInternalServerError
-	}
-	c.AbortWithStatusJSON(status, gin.H{"err": fmt.Sprintf("%s %s", errPrefix, err.Error())})
+// registerLegacy adds the routes before v1, kept as aliases for existing clients
+func registerLegacy(router *gin.Engine, auth *authenticator, gormDB *gorm.DB, services Services) {
+	ops := newOperations(gormDB, services, auth)
+	r := Rides{operations: ops}
+	router.GET("/ride", deprecated("/v1/rides"), auth.require(RoleOperator, RoleGate, RoleGuest), r.List)
+	router.POST("/ride/add", deprecated("/v1/rides"), auth.require(RoleAdmin), r.Add)
+	router.POST("/ride/update", deprecated("/v1/rides/{id}"), auth.require(RoleAdmin), r.Update)
+	router.POST("/ride/status", deprecated("/v1/rides/{id}/status"), auth.require(RoleOperator), r.Status)
+	router.DELETE("/ride/:id", deprecated("/v1/rides/{id}"), auth.require(RoleAdmin), r.Retire)
+	router.POST("/ride/restore", deprecated("/v1/rides/{id}/restore"), auth.require(RoleAdmin), r.Restore)
+
+	t := Tickets{operations: ops}
+	router.POST("/ticket/add", deprecated("/v1/tickets"), auth.require(RoleGate), t.Add)
+	router.GET("/ticket/:code", deprecated("/v1/tickets/{code}"), auth.require(RoleGate), t.Get)
+
+	c := Customers{operations: ops}
+	router.GET("/customer", deprecated("/v1/customers"), auth.require(RoleOperator, RoleGate), c.List)
+	router.POST("/customer/enter", deprecated("/v1/customers"), auth.require(RoleGate), c.Enter)
+	router.POST("/customer/exit", deprecated("/v1/customers/{id}/exit"), auth.require(RoleGate), c.Exit)
+	router.POST("/customer/queue", deprecated("/v1/customers/{id}/queue"), auth.require(RoleOperator, RoleGuest), c.Queue)
+	router.POST("/customer/unqueue", deprecated("/v1/customers/{id}/unqueue"), auth.require(RoleOperator, RoleGuest), c.UnQueue)
+	router.GET("/customer/:id/recommendations", deprecated("/v1/customers/{id}/recommendations"), auth.require(RoleOperator, RoleGuest), c.Recommendations)
+
+	p := Parties{operations: ops}
+	router.POST("/party/add", deprecated("/v1/parties"), auth.require(RoleGate), p.Add)
+	router.GET("/party/:id", deprecated("/v1/parties/{id}"), auth.require(RoleOperator, RoleGate), p.Get)
+	router.POST("/party/queue", deprecated("/v1/parties/{id}/queue"), auth.require(RoleOperator, RoleGuest), p.Queue)
 }
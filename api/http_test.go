@@ -7,17 +7,29 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jonboulle/clockwork"
 	"gitlab.com/therako/universal-studios/api"
 	"gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/idempotency"
+	"gitlab.com/therako/universal-studios/data/parties"
 	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var (
-	testConfig = api.Config{HTTPPort: 8081}
+	// Caches are sized as when serving, so routes are tested on cached states
+	testConfig = api.Config{
+		HTTPPort:                 8081,
+		AuthDisabled:             true,
+		CustomerCacheNumCounters: 1000,
+		CustomerCacheMaxCost:     1 << 20,
+		RideCacheNumCounters:     1000,
+		RideCacheMaxCost:         1 << 20,
+	}
 	gormLogger = logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
 		logger.Config{
@@ -32,13 +44,25 @@ func init() {
 	gin.SetMode(gin.TestMode)
 }
 
+// withFakeClock returns a copy of the config on a fake clock, for routers telling the time with it
+func withFakeClock(config api.Config) (api.Config, clockwork.FakeClock) {
+	fake := clockwork.NewFakeClock()
+	config.Clock = fake
+	return config, fake
+}
+
 func testDB(name string) *gorm.DB {
 	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{
 		Logger: gormLogger,
 	})
 	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
+	sqlDB, _ := gormDB.DB()
+	sqlDB.SetMaxOpenConns(1) // Every connection opens a new in-memory DB
 	gormDB.AutoMigrate(&rides.Ride{})
 	gormDB.AutoMigrate(&customers.Customer{})
+	gormDB.AutoMigrate(&parties.Party{})
+	gormDB.AutoMigrate(&tickets.Ticket{})
 	gormDB.AutoMigrate(&events.Event{})
+	gormDB.AutoMigrate(&idempotency.Response{})
 	return gormDB
 }
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/idempotency"
+	"gitlab.com/therako/universal-studios/logging"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyHeader lets clients safely retry POST requests, eg. turnstiles on flaky Wi-Fi
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotent replays the first response sent for an idempotency key instead of handling the
+// request again, so retries don't create duplicate customers, rides or events
+type idempotent struct {
+	DAO    idempotency.DAO
+	window time.Duration
+	// Clock tells which stored responses are in the replay window, the real clock when nil
+	Clock clock.Clock
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+func newIdempotent(config Config, gormDB *gorm.DB, clk clock.Clock) *idempotent {
+	return &idempotent{
+		DAO:      idempotency.DAO{DB: gormDB, Clock: clk},
+		window:   time.Duration(config.IdempotencyWindowSecs) * time.Second,
+		Clock:    clk,
+		inFlight: map[string]bool{},
+	}
+}
+
+// enabled is false when the replay window is zero
+func (i *idempotent) enabled() bool {
+	return i.window > 0
+}
+
+// handle is a middleware for all routes, only POST requests with an Idempotency-Key are handled
+func (i *idempotent) handle(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if c.Request.Method != http.MethodPost || key == "" {
+		return
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	// Keys are scoped to the route & the caller's credentials, a key never replays someone else's response
+	scopedKey := hash(c.Request.URL.Path, c.GetHeader("X-API-Key"), c.GetHeader("Authorization"), key)
+	requestHash := hash(c.Request.URL.RawQuery, string(body))
+
+	if !i.begin(scopedKey) {
+		abortWithError(c, http.StatusConflict, CodeConflict, "A request with this Idempotency-Key is in progress")
+		return
+	}
+	defer i.end(scopedKey)
+
+	stored, err := i.DAO.Get(scopedKey, clock.Or(i.Clock).Now().Add(-i.window))
+	switch {
+	case err == nil && stored.RequestHash != requestHash:
+		abortWithError(c, http.StatusUnprocessableEntity, CodeInvalidRequest, "Idempotency-Key was already used with a different request")
+		return
+	case err == nil:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+		c.Abort()
+		return
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		handleError(c, err, "idempotency")
+		return
+	}
+
+	recorder := &bodyRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	// Server errors aren't stored, the request can be retried with the same key
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+	err = i.DAO.Save(&idempotency.Response{
+		Key:         scopedKey,
+		RequestHash: requestHash,
+		Status:      recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to store response", "idempotency_key", key, "err", err)
+	}
+}
+
+// begin marks the key in flight, false when a request with the key is already being handled
+func (i *idempotent) begin(key string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.inFlight[key] {
+		return false
+	}
+	i.inFlight[key] = true
+	return true
+}
+
+func (i *idempotent) end(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.inFlight, key)
+}
+
+// cleanup removes stored responses once they're out of the replay window, till ctx is done
+func (i *idempotent) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(i.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := i.DAO.DeleteBefore(clock.Or(i.Clock).Now().Add(-i.window))
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to clean up idempotency keys", "err", err)
+			}
+		}
+	}
+}
+
+// bodyRecorder keeps a copy of the response body as it's written
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, value := range values {
+		h.Write([]byte(value))
+		// Separator so ("ab", "c") & ("a", "bc") hash differently
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
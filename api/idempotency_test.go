@@ -0,0 +1,152 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gotest.tools/v3/assert"
+)
+
+var idempotentConfig = api.Config{
+	HTTPPort:                 8081,
+	IdempotencyWindowSecs:    60,
+	AuthDisabled:             true,
+	CustomerCacheNumCounters: 1000,
+	CustomerCacheMaxCost:     1 << 20,
+	RideCacheNumCounters:     1000,
+	RideCacheMaxCost:         1 << 20,
+}
+
+func postForm(router http.Handler, path string, form url.Values, headers ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotentCustomerEnter(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&customers.Customer{Model: models.Model{ID: 359}})
+	tickets.DAO{DB: db}.Add(&tickets.Ticket{Code: "retry", ValidFrom: time.Now().Add(-time.Hour), ValidTill: time.Now().Add(time.Hour)})
+	router := api.New(context.Background(), idempotentConfig, db)
+	form := url.Values{"code": {"retry"}}
+
+	first := postForm(router, "/customer/enter", form, api.IdempotencyKeyHeader, "turnstile-1-42")
+	assert.Equal(t, 200, first.Code)
+	assert.Equal(t, `{"customer_id":360,"status":"added"}`, first.Body.String())
+
+	retry := postForm(router, "/customer/enter", form, api.IdempotencyKeyHeader, "turnstile-1-42")
+	assert.Equal(t, 200, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+
+	var count int64
+	db.Table(customers.TableName).Count(&count)
+	assert.Equal(t, int64(2), count)
+	ticket, _ := tickets.DAO{DB: db}.GetByCode("retry")
+	assert.Equal(t, uint(1), ticket.ScanCount)
+
+	// Without a key the request is handled again
+	w := postForm(router, "/customer/enter", form)
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, "", w.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotentRideAdd(t *testing.T) {
+	t.Run("expected a single ride on retries", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), idempotentConfig, db)
+		body := `{"name":"DareDevil","ride_time_secs":300,"capacity":20}`
+
+		first := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1")
+		retry := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1")
+
+		assert.Equal(t, 201, first.Code)
+		assert.Equal(t, 201, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		allRides, _ := rides.DAO{DB: db}.List(rides.Filter{})
+		assert.Equal(t, 1, len(allRides))
+
+		other := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-2")
+		assert.Equal(t, 201, other.Code)
+		allRides, _ = rides.DAO{DB: db}.List(rides.Filter{})
+		assert.Equal(t, 2, len(allRides))
+	})
+
+	t.Run("error when a key is reused with a different request", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), idempotentConfig, db)
+
+		serveJSON(router, "POST", "/v1/rides", `{"name":"DareDevil","ride_time_secs":300,"capacity":20}`, api.IdempotencyKeyHeader, "add-1")
+		w := serveJSON(router, "POST", "/v1/rides", `{"name":"Dragon","ride_time_secs":300,"capacity":20}`, api.IdempotencyKeyHeader, "add-1")
+
+		assert.Equal(t, 422, w.Code)
+		assert.Equal(t, `{"error":{"code":"invalid_request","message":"Idempotency-Key was already used with a different request"}}`, w.Body.String())
+	})
+
+	t.Run("expected the request to be handled again once out of the window", func(t *testing.T) {
+		db := testDB(t.Name())
+		config, clock := withFakeClock(idempotentConfig)
+		router := api.New(context.Background(), config, db)
+		body := `{"name":"DareDevil","ride_time_secs":300,"capacity":20}`
+
+		serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1")
+		clock.Advance(30 * time.Second)
+		assert.Equal(t, "true", serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1").Header().Get("Idempotent-Replayed"))
+		clock.Advance(time.Minute)
+		w := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1")
+
+		assert.Equal(t, 201, w.Code)
+		assert.Equal(t, "", w.Header().Get("Idempotent-Replayed"))
+		allRides, _ := rides.DAO{DB: db}.List(rides.Filter{})
+		assert.Equal(t, 2, len(allRides))
+	})
+}
+
+func TestIdempotentQueueWritesNoNewEvents(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&customers.Customer{Model: models.Model{ID: 361}})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3601}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	router := api.New(context.Background(), idempotentConfig, db)
+
+	for i := 0; i < 3; i++ {
+		w := serveJSON(router, "POST", "/v1/customers/361/queue", `{"ride_id":3601}`, api.IdempotencyKeyHeader, "queue-1")
+		assert.Equal(t, 200, w.Code)
+	}
+
+	var count int64
+	db.Table(events.TableName).Count(&count)
+	// One CustomerQueued & one RideCustomerQueued
+	assert.Equal(t, int64(2), count)
+}
+
+func TestIdempotencyKeysAreScopedToTheCaller(t *testing.T) {
+	db := testDB(t.Name())
+	config := authConfig
+	config.IdempotencyWindowSecs = 60
+	router := api.New(context.Background(), config, db)
+	body := `{"name":"DareDevil","ride_time_secs":300,"capacity":20}`
+
+	denied := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1", "X-API-Key", "gate-key")
+	assert.Equal(t, 403, denied.Code)
+
+	w := serveJSON(router, "POST", "/v1/rides", body, api.IdempotencyKeyHeader, "add-1", "X-API-Key", "admin-key")
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "", w.Header().Get("Idempotent-Replayed"))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/domain"
+)
+
+// NextCursorHeader is set on the list routes which respond with a plain list
+const NextCursorHeader = "X-Next-Cursor"
+
+// Errors
+var (
+	errInvalidCursor = domain.NewError(domain.Invalid, "invalid_cursor", "Invalid cursor, expected the next_cursor of a previous page")
+)
+
+// encodeCursor returns an opaque cursor for the page after the given id, empty on the last page
+func encodeCursor(after uint) string {
+	if after == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(after), 10)))
+}
+
+func decodeCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	after, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || after == 0 {
+		return 0, errInvalidCursor
+	}
+	return uint(after), nil
+}
+
+// secondsP converts optional seconds to a duration
+func secondsP(secs *uint) *time.Duration {
+	if secs == nil {
+		return nil
+	}
+	d := time.Duration(*secs) * time.Second
+	return &d
+}
+
+// rideSort parses a sort param, a leading - sorts descending
+func rideSort(sort string) (rides.Sort, bool) {
+	if strings.HasPrefix(sort, "-") {
+		return rides.Sort(sort[1:]), true
+	}
+	return rides.Sort(sort), false
+}
@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/api/pb"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gotest.tools/v3/assert"
+)
+
+func customerIDs(t *testing.T, body []byte) (ids []uint, next string) {
+	response := api.CustomerListResponse{}
+	assert.NilError(t, json.Unmarshal(body, &response))
+	for _, customer := range response.Customers {
+		ids = append(ids, customer.ID)
+	}
+	return ids, response.NextCursor
+}
+
+func TestV1CustomerList(t *testing.T) {
+	db := testDB(t.Name())
+	yesterday := time.Now().Add(-24 * time.Hour)
+	db.Create(&rides.Ride{Model: models.Model{ID: 3801}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	for id := uint(380); id <= 384; id++ {
+		db.Create(&customers.Customer{Model: models.Model{ID: id}})
+	}
+	db.Create(&customers.Customer{Model: models.Model{ID: 385, CreatedAt: yesterday}, ExitAt: models.TimeP(yesterday)})
+	router := api.New(context.Background(), testConfig, db)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/381/queue", `{"ride_id":3801}`).Code)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/382/queue", `{"ride_id":3801}`).Code)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/382/unqueue", ``).Code)
+
+	t.Run("expected to page through customers inside the studio", func(t *testing.T) {
+		w := serveJSON(router, "GET", "/v1/customers?limit=2", "")
+		assert.Equal(t, 200, w.Code)
+		ids, next := customerIDs(t, w.Body.Bytes())
+		assert.DeepEqual(t, []uint{380, 381}, ids)
+		assert.Assert(t, next != "")
+
+		w = serveJSON(router, "GET", "/v1/customers?limit=2&cursor="+next, "")
+		ids, next = customerIDs(t, w.Body.Bytes())
+		assert.DeepEqual(t, []uint{382, 383}, ids)
+
+		w = serveJSON(router, "GET", "/v1/customers?limit=2&cursor="+next, "")
+		ids, next = customerIDs(t, w.Body.Bytes())
+		assert.DeepEqual(t, []uint{384}, ids)
+		assert.Equal(t, "", next)
+	})
+
+	t.Run("expected to filter customers", func(t *testing.T) {
+		for query, expected := range map[string][]uint{
+			"status=exited":  {385},
+			"status=all":     {380, 381, 382, 383, 384, 385},
+			"queueing=true":  {381},
+			"queueing=false": {380, 382, 383, 384},
+			"status=all&created_till=" + url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)): {385},
+			"created_from=" + url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)):            {380, 381, 382, 383, 384},
+		} {
+			w := serveJSON(router, "GET", "/v1/customers?"+query, "")
+			assert.Equal(t, 200, w.Code, query)
+			ids, _ := customerIDs(t, w.Body.Bytes())
+			assert.DeepEqual(t, expected, ids)
+		}
+	})
+
+	t.Run("error on invalid params", func(t *testing.T) {
+		for query, code := range map[string]string{
+			"cursor=abc":     "invalid_cursor",
+			"status=left":    "unknown_customer_status",
+			"limit=5000":     "invalid_page_size",
+			"queueing=maybe": api.CodeInvalidRequest,
+		} {
+			w := serveJSON(router, "GET", "/v1/customers?"+query, "")
+			assert.Equal(t, 400, w.Code, query)
+			response := api.ErrorResponse{}
+			assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, code, response.Error.Code)
+		}
+	})
+
+	t.Run("expected the next cursor in a header on the older route", func(t *testing.T) {
+		w := serveJSON(router, "GET", "/customer?limit=4", "")
+		assert.Equal(t, 200, w.Code)
+		var page []*customers.Customer
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, 4, len(page))
+
+		w = serveJSON(router, "GET", "/customer?limit=4&cursor="+w.Header().Get(api.NextCursorHeader), "")
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, 1, len(page))
+		assert.Equal(t, uint(384), page[0].ID)
+		assert.Equal(t, "", w.Header().Get(api.NextCursorHeader))
+	})
+
+	t.Run("expected gRPC to page with tokens", func(t *testing.T) {
+		client := pb.NewCustomerServiceClient(testGRPC(t, testConfig, db))
+
+		page, err := client.ListCustomers(context.Background(), &pb.ListCustomersRequest{PageSize: 3, Status: "all"})
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(page.Customers))
+		page, err = client.ListCustomers(context.Background(), &pb.ListCustomersRequest{PageSize: 3, Status: "all", PageToken: page.NextPageToken})
+		assert.NilError(t, err)
+		assert.Equal(t, 3, len(page.Customers))
+		assert.Equal(t, uint32(385), page.Customers[2].Id)
+		assert.Equal(t, "", page.NextPageToken)
+
+		_, err = client.ListCustomers(context.Background(), &pb.ListCustomersRequest{PageToken: "abc"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestV1RideSort(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3802}, Name: "Carousel", Capacity: 1, RideTime: 10 * time.Minute})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3803}, Name: "Alpha", Capacity: 1, RideTime: 5 * time.Minute})
+	db.Create(&rides.Ride{Model: models.Model{ID: 3804}, Name: "Bravo", Capacity: 1, RideTime: 5 * time.Minute})
+	db.Create(&customers.Customer{Model: models.Model{ID: 386}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 387}})
+	router := api.New(context.Background(), testConfig, db)
+	// Carousel waits longest, then Bravo
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/386/queue", `{"ride_id":3802}`).Code)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/387/queue", `{"ride_id":3804}`).Code)
+
+	for query, expected := range map[string][]uint{
+		"":           {3802, 3803, 3804},
+		"sort=name":  {3803, 3804, 3802},
+		"sort=-name": {3802, 3804, 3803},
+		"sort=wait":  {3803, 3804, 3802},
+		"sort=-wait": {3802, 3804, 3803},
+	} {
+		w := serveJSON(router, "GET", "/v1/rides?"+query, "")
+		assert.Equal(t, 200, w.Code, query)
+		response := []api.RideResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := []uint{}
+		for _, ride := range response {
+			ids = append(ids, ride.ID)
+		}
+		assert.DeepEqual(t, expected, ids)
+	}
+
+	t.Run("waits are sorted across pages", func(t *testing.T) {
+		ids := []uint{}
+		cursor := ""
+		for page := 0; page < 3; page++ {
+			w := serveJSON(router, "GET", "/v1/rides?sort=-wait&limit=1&cursor="+cursor, "")
+			assert.Equal(t, 200, w.Code)
+			response := []api.RideResponse{}
+			assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 1, len(response))
+			ids = append(ids, response[0].ID)
+			cursor = w.Header().Get(api.NextCursorHeader)
+		}
+		assert.DeepEqual(t, []uint{3802, 3804, 3803}, ids)
+		assert.Equal(t, "", cursor)
+	})
+
+	w := serveJSON(router, "GET", "/v1/rides?sort=thrill", "")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":{"code":"unknown_sort","message":"rides Unknown sort, expected name or wait"}}`, w.Body.String())
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/therako/universal-studios/logging"
+)
+
+// RequestIDHeader carries the request's ID, taken from the client when given so logs can be joined across services
+const RequestIDHeader = "X-Request-ID"
+
+// requestLogger sets a logger carrying the request ID on the request's context & logs each request once handled
+func requestLogger(c *gin.Context) {
+	start := time.Now()
+	requestID := c.GetHeader(RequestIDHeader)
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	c.Header(RequestIDHeader, requestID)
+
+	ctx := logging.With(c.Request.Context(), logging.RequestIDKey, requestID)
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	logging.FromContext(ctx).Info("Handled request",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"route", c.FullPath(),
+		"status", c.Writer.Status(),
+		"duration", time.Since(start),
+	)
+}
@@ -0,0 +1,67 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/logging"
+	"gotest.tools/v3/assert"
+)
+
+// logLines decodes the JSON logs written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	lines := []map[string]interface{}{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		line := map[string]interface{}{}
+		assert.NilError(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestLogging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(logging.New(buf, slog.LevelInfo))
+	defer slog.SetDefault(defaultLogger)
+
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3961}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	db.Create(&customers.Customer{Model: models.Model{ID: 397}})
+	router := api.New(context.Background(), authConfig, db)
+
+	w := serveJSON(router, "POST", "/v1/customers/397/queue", `{"ride_id":3961}`, "X-API-Key", "operator-key", api.RequestIDHeader, "req-397")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "req-397", w.Header().Get(api.RequestIDHeader))
+
+	var queued, handled map[string]interface{}
+	for _, line := range logLines(t, buf) {
+		switch line["msg"] {
+		case "Customer queued":
+			queued = line
+		case "Handled request":
+			handled = line
+		}
+	}
+	assert.Assert(t, queued != nil, "expected the queue to be logged")
+	assert.Equal(t, "req-397", queued[logging.RequestIDKey])
+	assert.Equal(t, float64(397), queued["customer_id"])
+	assert.Equal(t, float64(3961), queued["ride_id"])
+	assert.Assert(t, handled != nil, "expected the request to be logged")
+	assert.Equal(t, "req-397", handled[logging.RequestIDKey])
+	assert.Equal(t, "/v1/customers/:id/queue", handled["route"])
+
+	// Requests without an ID are given one
+	w = serveJSON(router, "GET", "/v1/rides", "", "X-API-Key", "operator-key")
+	assert.Equal(t, 200, w.Code)
+	assert.Assert(t, w.Header().Get(api.RequestIDHeader) != "")
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.com/therako/universal-studios/data/rides"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gitlab.com/therako/universal-studios/metrics"
+)
+
+// metricsHandler serves the package collectors, Go runtime metrics & the rides' current queues
+func metricsHandler(service *ridesEvents.RideService) gin.HandlerFunc {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.Collectors()...)
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	registry.MustRegister(newRideCollector(service))
+	return gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// observeLatency records how long each request took by route, unmatched routes are grouped together
+func observeLatency(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.HTTPDuration.
+		WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+		Observe(time.Since(start).Seconds())
+}
+
+// rideCollector reports the queue length & waiting time of every ride's queues when scraped,
+// waits change as time passes so they're read from the ride states rather than set on events
+type rideCollector struct {
+	Service   *ridesEvents.RideService
+	queueDesc *prometheus.Desc
+	waitDesc  *prometheus.Desc
+}
+
+func newRideCollector(service *ridesEvents.RideService) *rideCollector {
+	labels := []string{"ride_id", "ride", "queue_type"}
+	return &rideCollector{
+		Service:   service,
+		queueDesc: prometheus.NewDesc("studios_ride_queue_length", "Customers in the ride's queue.", labels, nil),
+		waitDesc:  prometheus.NewDesc("studios_ride_wait_seconds", "Estimated waiting time of the ride's queue.", labels, nil),
+	}
+}
+
+func (r *rideCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.queueDesc
+	ch <- r.waitDesc
+}
+
+func (r *rideCollector) Collect(ch chan<- prometheus.Metric) {
+	allRides, err := r.Service.List(context.Background(), rides.Filter{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(r.queueDesc, err)
+		return
+	}
+
+	for _, ride := range allRides {
+		id := strconv.Itoa(int(ride.ID))
+		r.collectQueue(ch, id, ride.Name, string(ridesEvents.Standby), ride.InQueue, ride.EstimatedWaitingTime)
+		for _, queueType := range ride.QueueTypes {
+			r.collectQueue(ch, id, ride.Name, queueType, ride.QueueInCounts[queueType], ride.QueueWaitingTimes[queueType])
+		}
+	}
+}
+
+func (r *rideCollector) collectQueue(ch chan<- prometheus.Metric, id, name, queueType string, count uint, wait time.Duration) {
+	ch <- prometheus.MustNewConstMetric(r.queueDesc, prometheus.GaugeValue, float64(count), id, name, queueType)
+	ch <- prometheus.MustNewConstMetric(r.waitDesc, prometheus.GaugeValue, wait.Seconds(), id, name, queueType)
+}
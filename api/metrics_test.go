@@ -0,0 +1,43 @@
+package api_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gotest.tools/v3/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3951}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider"}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 395}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 396}})
+	router := api.New(context.Background(), authConfig, db)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/395/queue", `{"ride_id":3951}`, "X-API-Key", "operator-key").Code)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/396/queue", `{"ride_id":3951}`, "X-API-Key", "operator-key").Code)
+
+	w := serveJSON(router, "GET", "/metrics", "")
+	assert.Equal(t, 200, w.Code, "expected metrics to be public")
+	body := w.Body.String()
+	for _, expected := range []string{
+		`studios_events_appended_total{aggregate="Customer",name="CustomerQueued"}`,
+		`studios_events_appended_total{aggregate="Ride",name="RideCustomerQueued"}`,
+		`studios_cache_lookups_total{aggregate="Ride",result="miss"}`,
+		`studios_cache_lookups_total{aggregate="Customer",result="miss"}`,
+		`studios_aggregate_replay_duration_seconds_count{aggregate="Ride"}`,
+		`studios_aggregate_replay_events_bucket{aggregate="Customer",le="1"}`,
+		`studios_http_request_duration_seconds_count{method="POST",route="/v1/customers/:id/queue",status="200"}`,
+		`studios_ride_queue_length{queue_type="standby",ride="ride1",ride_id="3951"} 2`,
+		// Second customer boards with the second batch, ~20 mins away
+		`studios_ride_wait_seconds{queue_type="standby",ride="ride1",ride_id="3951"} 119`,
+		`studios_ride_queue_length{queue_type="single_rider",ride="ride1",ride_id="3951"} 0`,
+	} {
+		assert.Assert(t, strings.Contains(body, expected), "expected %s in metrics", expected)
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPISpec builds an OpenAPI 3 document describing the routes, schemas are reflected from the
+// request & response types using their json, form & binding tags
+func openAPISpec(basePath string, routes []route) map[string]interface{} {
+	g := &specGenerator{schemas: map[string]interface{}{}}
+	g.schemaFor(reflect.TypeOf(ErrorResponse{}))
+
+	paths := map[string]map[string]interface{}{}
+	for _, r := range routes {
+		path := openAPIPath(basePath + r.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(r.Method)] = g.operation(r)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Universal Studios",
+			"version":     "1.0.0",
+			"description": "Rides, queues & customers of the studio",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey":     map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"guestToken": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"guestToken": []string{}},
+		},
+	}
+}
+
+// openAPIPath converts gin's `:param` segments to `{param}`
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type specGenerator struct {
+	schemas map[string]interface{}
+}
+
+func (g *specGenerator) operation(r route) map[string]interface{} {
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	roles := make([]string, 0, len(r.Roles))
+	for _, role := range r.Roles {
+		roles = append(roles, string(role))
+	}
+	op := map[string]interface{}{
+		"summary":     r.Summary,
+		"description": "Allowed roles: " + strings.Join(roles, ", ") + ". Admins can call every route.",
+		"responses": map[string]interface{}{
+			strconv.Itoa(status): g.content(http.StatusText(status), r.Response),
+			"default":            g.content("Error", ErrorResponse{}),
+		},
+	}
+
+	parameters := []interface{}{}
+	for _, segment := range strings.Split(r.Path, "/") {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		schema := map[string]interface{}{"type": "string"}
+		if segment == ":id" {
+			schema = map[string]interface{}{"type": "integer", "minimum": 1}
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name": segment[1:], "in": "path", "required": true, "schema": schema,
+		})
+	}
+	if r.Query != nil {
+		t := reflect.TypeOf(r.Query)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := tagName(field.Tag.Get("form"))
+			if name == "" {
+				continue
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name": name, "in": "query", "required": isRequired(field), "schema": g.schemaFor(field.Type),
+			})
+		}
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	if r.Request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.schemaFor(reflect.TypeOf(r.Request))},
+			},
+		}
+	}
+	return op
+}
+
+func (g *specGenerator) content(description string, body interface{}) map[string]interface{} {
+	response := map[string]interface{}{"description": description}
+	if body != nil {
+		response["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": g.schemaFor(reflect.TypeOf(body))},
+		}
+	}
+	return response
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// schemaFor returns the schema of a type, named structs are added to the components & referenced
+func (g *specGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		schema := g.schemaFor(t.Elem())
+		if _, ok := schema["$ref"]; !ok {
+			schema["nullable"] = true
+		}
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "Duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// Placeholder while the fields are walked, in case the type refers to itself
+			g.schemas[t.Name()] = map[string]interface{}{}
+			g.schemas[t.Name()] = g.objectSchema(t)
+		}
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+func (g *specGenerator) objectSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := tagName(field.Tag.Get("json"))
+		if name == "" || field.PkgPath != "" {
+			continue
+		}
+		properties[name] = g.schemaFor(field.Type)
+		if isRequired(field) {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func tagName(tag string) string {
+	name := strings.Split(tag, ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
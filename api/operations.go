@@ -0,0 +1,409 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/parties"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gitlab.com/therako/universal-studios/domain"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gorm.io/gorm"
+)
+
+// Errors
+var (
+	errRideIncomplete      = domain.NewError(domain.Invalid, CodeInvalidRequest, "Expected atleast name, capacity & ride_time_secs")
+	errRideZeroCapacity    = domain.NewError(domain.Invalid, CodeInvalidRequest, "capacity & ride_time_secs can't be zero")
+	errInvalidValidFrom    = domain.NewError(domain.Invalid, CodeInvalidRequest, "valid_from must be a date in YYYY-MM-DD")
+	errInvalidValidTill    = domain.NewError(domain.Invalid, CodeInvalidRequest, "valid_till must be a date in YYYY-MM-DD")
+	errTicketDatesReversed = domain.NewError(domain.Invalid, CodeInvalidRequest, "valid_till can't be before valid_from")
+	errUnknownStrategy     = domain.NewError(domain.Invalid, CodeInvalidRequest, "Unknown strategy")
+	errNotTheCustomer      = domain.NewError(domain.Forbidden, CodeForbidden, ErrForbidden.Error())
+)
+
+// operations are the studio's use cases, shared by the legacy & v1 routes & the gRPC services so
+// they only differ in how they bind the input & respond
+type operations struct {
+	RideDAO     rides.DAO
+	TicketDAO   tickets.DAO
+	CustomerDAO customers.DAO
+	PartyDAO    parties.DAO
+	Services    Services
+	// Clock tells the time waits are estimated at & tickets are issued on, the real clock when nil
+	Clock clock.Clock
+	auth  *authenticator
+}
+
+func newOperations(gormDB *gorm.DB, services Services, auth *authenticator) operations {
+	return operations{
+		RideDAO:     rides.DAO{DB: gormDB},
+		TicketDAO:   tickets.DAO{DB: gormDB},
+		CustomerDAO: services.Customers.DAO,
+		PartyDAO:    parties.DAO{DB: gormDB},
+		Services:    services,
+		Clock:       services.Rides.Clock,
+		auth:        auth,
+	}
+}
+
+// failure is an error from a step of an operation, the step prefixes the error's message
+type failure struct {
+	step string
+	err  error
+}
+
+func fail(step string, err error) error {
+	return &failure{step: step, err: err}
+}
+
+func (f *failure) Error() string {
+	return fmt.Sprintf("%s %s", f.step, f.err.Error())
+}
+
+func (f *failure) Unwrap() error {
+	return f.err
+}
+
+// authorizeCustomers errors when a guest tries to act as none of the customers
+func authorizeCustomers(ctx context.Context, customerIDs ...uint) error {
+	p, ok := ctx.Value(principalContextKey{}).(*principal)
+	if !ok || p.actsAs(customerIDs...) {
+		// Auth is disabled or the caller isn't a guest
+		return nil
+	}
+	return errNotTheCustomer
+}
+
+// withState fills the ride with its current waiting times
+func (o operations) withState(ctx context.Context, ride *rides.Ride) error {
+	rideState, err := o.Services.Rides.GetCurrentState(ctx, ride)
+	if err != nil {
+		return fail("ride", err)
+	}
+	rideState.Apply(ride, clock.Or(o.Clock).Now())
+	return nil
+}
+
+func (o operations) addRide(input AddRideRequest) (*rides.Ride, error) {
+	if input.Name == "" || input.RideTimeSecs == 0 || input.Capacity == 0 {
+		return nil, errRideIncomplete
+	}
+	err := ridesEvents.ValidateQueueTypes(input.QueueTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	ride := &rides.Ride{
+		Name:               input.Name,
+		Desc:               input.Desc,
+		Capacity:           input.Capacity,
+		RideTime:           time.Duration(input.RideTimeSecs) * time.Second,
+		Zone:               input.Zone,
+		MinHeightCm:        input.MinHeightCm,
+		MinAge:             input.MinAge,
+		ThrillLevel:        input.ThrillLevel,
+		Accessibility:      input.Accessibility,
+		Tags:               input.Tags,
+		QueueTypes:         input.QueueTypes,
+		PriorityMergeRatio: input.PriorityMergeRatio,
+	}
+	err = o.RideDAO.Add(ride)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+	return ride, nil
+}
+
+func (o operations) updateRide(id uint, input UpdateRideRequest) (*rides.Ride, error) {
+	ride, err := o.RideDAO.Get(id)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+
+	if input.Name != nil {
+		ride.Name = *input.Name
+	}
+	if input.Desc != nil {
+		ride.Desc = *input.Desc
+	}
+	if input.RideTimeSecs != nil {
+		ride.RideTime = time.Duration(*input.RideTimeSecs) * time.Second
+	}
+	if input.Capacity != nil {
+		ride.Capacity = *input.Capacity
+	}
+	if input.Zone != nil {
+		ride.Zone = *input.Zone
+	}
+	if input.MinHeightCm != nil {
+		ride.MinHeightCm = *input.MinHeightCm
+	}
+	if input.MinAge != nil {
+		ride.MinAge = *input.MinAge
+	}
+	if input.ThrillLevel != nil {
+		ride.ThrillLevel = *input.ThrillLevel
+	}
+	if input.Accessibility != nil {
+		ride.Accessibility = input.Accessibility
+	}
+	if input.Tags != nil {
+		ride.Tags = input.Tags
+	}
+	if input.QueueTypes != nil {
+		err = ridesEvents.ValidateQueueTypes(input.QueueTypes)
+		if err != nil {
+			return nil, err
+		}
+		ride.QueueTypes = input.QueueTypes
+	}
+	if input.PriorityMergeRatio != nil {
+		ride.PriorityMergeRatio = *input.PriorityMergeRatio
+	}
+	if ride.RideTime == 0 || ride.Capacity == 0 {
+		return nil, errRideZeroCapacity
+	}
+
+	err = o.RideDAO.Update(ride)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+	return ride, nil
+}
+
+func (o operations) setRideStatus(ctx context.Context, id uint, down bool) (*rides.Ride, error) {
+	ride, err := o.RideDAO.Get(id)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+
+	err = o.Services.Rides.LogRideStatus(ctx, ride, down)
+	if err != nil {
+		return nil, fail("status", err)
+	}
+	return ride, nil
+}
+
+func (o operations) retireRide(ctx context.Context, id uint) (*rides.Ride, []*customers.Customer, error) {
+	ride, err := o.RideDAO.Get(id)
+	if err != nil {
+		return nil, nil, fail("ride", err)
+	}
+
+	unQueued, err := o.Services.Customers.RetireRide(ctx, ride)
+	if err != nil {
+		return nil, nil, fail("retire", err)
+	}
+	return ride, unQueued, nil
+}
+
+func (o operations) restoreRide(ctx context.Context, id uint) (*rides.Ride, error) {
+	ride, err := o.RideDAO.Get(id)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+
+	err = o.Services.Rides.LogRideRestored(ctx, ride)
+	if err != nil {
+		return nil, fail("restore", err)
+	}
+	return ride, nil
+}
+
+// ticketDetails of a ticket to issue, defaults to a day ticket for today
+type ticketDetails struct {
+	Code string `form:"code"`
+	Type string `form:"type"`
+	// Dates the ticket is valid on, both inclusive
+	ValidFrom time.Time `form:"valid_from" time_format:"2006-01-02"`
+	ValidTill time.Time `form:"valid_till" time_format:"2006-01-02"`
+	ReEntry   string    `form:"re_entry"`
+}
+
+func (o operations) addTicket(input ticketDetails) (*tickets.Ticket, error) {
+	if input.Type == "" {
+		input.Type = "day"
+	}
+	if input.ValidFrom.IsZero() {
+		year, month, day := clock.Or(o.Clock).Now().Date()
+		input.ValidFrom = time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	if input.ValidTill.IsZero() {
+		input.ValidTill = input.ValidFrom
+	}
+	if input.ValidTill.Before(input.ValidFrom) {
+		return nil, errTicketDatesReversed
+	}
+
+	ticket := &tickets.Ticket{
+		Code:      input.Code,
+		Type:      input.Type,
+		ValidFrom: input.ValidFrom,
+		// Valid till the end of the day
+		ValidTill: input.ValidTill.AddDate(0, 0, 1),
+		ReEntry:   tickets.ReEntryPolicy(input.ReEntry),
+	}
+	err := o.TicketDAO.Add(ticket)
+	if err != nil {
+		return nil, fail("ticket", err)
+	}
+	return ticket, nil
+}
+
+// enter lets the customer in with their ticket, token is set when guest tokens are enabled for the
+// customer to queue for rides as themselves
+func (o operations) enter(code string) (customer *customers.Customer, reEntered bool, token string, err error) {
+	customer, reEntered, err = o.CustomerDAO.Enter(code)
+	if err != nil {
+		return nil, false, "", fail("ticket", err)
+	}
+
+	if o.auth != nil && len(o.auth.jwtSecret) > 0 {
+		token, err = o.auth.issueGuestToken(customer.ID)
+		if err != nil {
+			return nil, false, "", fail("token", err)
+		}
+	}
+	return customer, reEntered, token, nil
+}
+
+func (o operations) exit(id uint) (*customers.Customer, error) {
+	customer, err := o.CustomerDAO.Exit(id)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+	return customer, nil
+}
+
+// queue adds the customer to a queue of the ride, standby by default
+func (o operations) queue(ctx context.Context, id uint, rideID uint, queueType string) (*customers.Customer, error) {
+	err := authorizeCustomers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := o.CustomerDAO.Get(id)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+
+	ride, err := o.RideDAO.Get(rideID)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+
+	if queueType == "" {
+		queueType = string(ridesEvents.Standby)
+	}
+	err = o.Services.Customers.LogCustomerInQueueOfType(ctx, customer, ride, ridesEvents.QueueType(queueType))
+	if err != nil {
+		return nil, fail("queue", err)
+	}
+	return customer, nil
+}
+
+func (o operations) unQueue(ctx context.Context, id uint) (*customers.Customer, error) {
+	err := authorizeCustomers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := o.CustomerDAO.Get(id)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+
+	err = o.Services.Customers.LogCustomerLeftAQueue(ctx, customer)
+	if err != nil {
+		return nil, fail("un-queue", err)
+	}
+	return customer, nil
+}
+
+// customerState returns the current queue state of the customer
+func (o operations) customerState(ctx context.Context, id uint) (*customersEvents.CustomerState, error) {
+	err := authorizeCustomers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := o.CustomerDAO.Get(id)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+
+	state, err := o.Services.Customers.GetCurrentState(ctx, customer)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+	return state, nil
+}
+
+// recommend ranks the rides a customer can go to next by the named strategy, the default when empty
+func (o operations) recommend(ctx context.Context, id uint, strategyName string) ([]*rides.Ride, error) {
+	err := authorizeCustomers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategyName == "" {
+		strategyName = customersEvents.DefaultStrategy
+	}
+	strategy, ok := customersEvents.Strategies[strategyName]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknownStrategy, strategyName)
+	}
+
+	customer, err := o.CustomerDAO.Get(id)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+
+	recommended, err := o.Services.Customers.Recommend(ctx, customer, strategy)
+	if err != nil {
+		return nil, fail("recommendations", err)
+	}
+	return recommended, nil
+}
+
+func (o operations) addParty(name string, memberIDs []uint) (*parties.Party, error) {
+	party, err := o.PartyDAO.Create(name, memberIDs)
+	if err != nil {
+		return nil, fail("customer", err)
+	}
+	return party, nil
+}
+
+// queueParty adds all members of the party to the ride's standby queue together, guests can
+// queue their own party
+func (o operations) queueParty(ctx context.Context, id uint, rideID uint) (*parties.Party, error) {
+	party, err := o.PartyDAO.Get(id)
+	if err != nil {
+		return nil, fail("party", err)
+	}
+	memberIDs := make([]uint, 0, len(party.Members))
+	for _, member := range party.Members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+	err = authorizeCustomers(ctx, memberIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	ride, err := o.RideDAO.Get(rideID)
+	if err != nil {
+		return nil, fail("ride", err)
+	}
+
+	err = o.Services.Customers.LogPartyInQueue(ctx, party, ride)
+	if err != nil {
+		return nil, fail("queue", err)
+	}
+	return party, nil
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Parties struct {
+	operations
+}
+
+type partyAddForm struct {
+	Name      string `form:"name"`
+	MemberIDs []uint `form:"member_ids" binding:"required"`
+}
+
+// Add groups customers into a party so they can queue together
+func (r Parties) Add(c *gin.Context) {
+	var input partyAddForm
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	party, err := r.addParty(input.Name, input.MemberIDs)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "added", "party_id": party.ID, "size": party.Size()})
+}
+
+type partyURI struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+// Get returns the party with all its members
+func (r Parties) Get(c *gin.Context) {
+	var uri partyURI
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	party, err := r.PartyDAO.Get(uri.ID)
+	if err != nil {
+		handleError(c, err, "party")
+		return
+	}
+
+	c.JSON(http.StatusOK, party)
+}
+
+type partyQueueForm struct {
+	ID     uint `form:"id" binding:"required"`
+	RideID uint `form:"ride_id" binding:"required"`
+}
+
+// Queue marks all members of the party entering a queue for a ride together
+func (r Parties) Queue(c *gin.Context) {
+	var input partyQueueForm
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	party, err := r.queueParty(c.Request.Context(), input.ID, input.RideID)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "queued", "party_id": party.ID, "size": party.Size()})
+}
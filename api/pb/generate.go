@@ -0,0 +1,4 @@
+// Package pb holds the protobuf messages & gRPC services of the studios API
+package pb
+
+//go:generate buf generate
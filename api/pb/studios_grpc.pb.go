@@ -0,0 +1,554 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// RideServiceClient is the client API for RideService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RideServiceClient interface {
+	// ListRides returns rides matching the filters along with their current state
+	ListRides(ctx context.Context, in *ListRidesRequest, opts ...grpc.CallOption) (*ListRidesResponse, error)
+	// AddRide adds a new ride to the studio
+	AddRide(ctx context.Context, in *AddRideRequest, opts ...grpc.CallOption) (*Ride, error)
+	// SetRideStatus marks a ride as down or back up
+	SetRideStatus(ctx context.Context, in *SetRideStatusRequest, opts ...grpc.CallOption) (*RideState, error)
+	// GetRideState returns the current queue state of a ride
+	GetRideState(ctx context.Context, in *GetRideStateRequest, opts ...grpc.CallOption) (*RideState, error)
+	// WatchRideState sends the current state of a ride and then every change to it
+	WatchRideState(ctx context.Context, in *WatchRideStateRequest, opts ...grpc.CallOption) (RideService_WatchRideStateClient, error)
+}
+
+type rideServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRideServiceClient(cc grpc.ClientConnInterface) RideServiceClient {
+	return &rideServiceClient{cc}
+}
+
+func (c *rideServiceClient) ListRides(ctx context.Context, in *ListRidesRequest, opts ...grpc.CallOption) (*ListRidesResponse, error) {
+	out := new(ListRidesResponse)
+	err := c.cc.Invoke(ctx, "/studios.v1.RideService/ListRides", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rideServiceClient) AddRide(ctx context.Context, in *AddRideRequest, opts ...grpc.CallOption) (*Ride, error) {
+	out := new(Ride)
+	err := c.cc.Invoke(ctx, "/studios.v1.RideService/AddRide", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rideServiceClient) SetRideStatus(ctx context.Context, in *SetRideStatusRequest, opts ...grpc.CallOption) (*RideState, error) {
+	out := new(RideState)
+	err := c.cc.Invoke(ctx, "/studios.v1.RideService/SetRideStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rideServiceClient) GetRideState(ctx context.Context, in *GetRideStateRequest, opts ...grpc.CallOption) (*RideState, error) {
+	out := new(RideState)
+	err := c.cc.Invoke(ctx, "/studios.v1.RideService/GetRideState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rideServiceClient) WatchRideState(ctx context.Context, in *WatchRideStateRequest, opts ...grpc.CallOption) (RideService_WatchRideStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RideService_serviceDesc.Streams[0], "/studios.v1.RideService/WatchRideState", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rideServiceWatchRideStateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RideService_WatchRideStateClient interface {
+	Recv() (*RideState, error)
+	grpc.ClientStream
+}
+
+type rideServiceWatchRideStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *rideServiceWatchRideStateClient) Recv() (*RideState, error) {
+	m := new(RideState)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RideServiceServer is the server API for RideService service.
+// All implementations must embed UnimplementedRideServiceServer
+// for forward compatibility
+type RideServiceServer interface {
+	// ListRides returns rides matching the filters along with their current state
+	ListRides(context.Context, *ListRidesRequest) (*ListRidesResponse, error)
+	// AddRide adds a new ride to the studio
+	AddRide(context.Context, *AddRideRequest) (*Ride, error)
+	// SetRideStatus marks a ride as down or back up
+	SetRideStatus(context.Context, *SetRideStatusRequest) (*RideState, error)
+	// GetRideState returns the current queue state of a ride
+	GetRideState(context.Context, *GetRideStateRequest) (*RideState, error)
+	// WatchRideState sends the current state of a ride and then every change to it
+	WatchRideState(*WatchRideStateRequest, RideService_WatchRideStateServer) error
+	mustEmbedUnimplementedRideServiceServer()
+}
+
+// UnimplementedRideServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRideServiceServer struct {
+}
+
+func (UnimplementedRideServiceServer) ListRides(context.Context, *ListRidesRequest) (*ListRidesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRides not implemented")
+}
+func (UnimplementedRideServiceServer) AddRide(context.Context, *AddRideRequest) (*Ride, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddRide not implemented")
+}
+func (UnimplementedRideServiceServer) SetRideStatus(context.Context, *SetRideStatusRequest) (*RideState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRideStatus not implemented")
+}
+func (UnimplementedRideServiceServer) GetRideState(context.Context, *GetRideStateRequest) (*RideState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRideState not implemented")
+}
+func (UnimplementedRideServiceServer) WatchRideState(*WatchRideStateRequest, RideService_WatchRideStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRideState not implemented")
+}
+func (UnimplementedRideServiceServer) mustEmbedUnimplementedRideServiceServer() {}
+
+// UnsafeRideServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RideServiceServer will
+// result in compilation errors.
+type UnsafeRideServiceServer interface {
+	mustEmbedUnimplementedRideServiceServer()
+}
+
+func RegisterRideServiceServer(s grpc.ServiceRegistrar, srv RideServiceServer) {
+	s.RegisterService(&_RideService_serviceDesc, srv)
+}
+
+func _RideService_ListRides_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRidesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RideServiceServer).ListRides(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.RideService/ListRides",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RideServiceServer).ListRides(ctx, req.(*ListRidesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RideService_AddRide_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RideServiceServer).AddRide(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.RideService/AddRide",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RideServiceServer).AddRide(ctx, req.(*AddRideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RideService_SetRideStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRideStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RideServiceServer).SetRideStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.RideService/SetRideStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RideServiceServer).SetRideStatus(ctx, req.(*SetRideStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RideService_GetRideState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRideStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RideServiceServer).GetRideState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.RideService/GetRideState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RideServiceServer).GetRideState(ctx, req.(*GetRideStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RideService_WatchRideState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRideStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RideServiceServer).WatchRideState(m, &rideServiceWatchRideStateServer{stream})
+}
+
+type RideService_WatchRideStateServer interface {
+	Send(*RideState) error
+	grpc.ServerStream
+}
+
+type rideServiceWatchRideStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *rideServiceWatchRideStateServer) Send(m *RideState) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _RideService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "studios.v1.RideService",
+	HandlerType: (*RideServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRides",
+			Handler:    _RideService_ListRides_Handler,
+		},
+		{
+			MethodName: "AddRide",
+			Handler:    _RideService_AddRide_Handler,
+		},
+		{
+			MethodName: "SetRideStatus",
+			Handler:    _RideService_SetRideStatus_Handler,
+		},
+		{
+			MethodName: "GetRideState",
+			Handler:    _RideService_GetRideState_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRideState",
+			Handler:       _RideService_WatchRideState_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "studios.proto",
+}
+
+// CustomerServiceClient is the client API for CustomerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CustomerServiceClient interface {
+	// ListCustomers returns all customers inside the studio
+	ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (*ListCustomersResponse, error)
+	// Enter validates the entry ticket and lets the customer in
+	Enter(ctx context.Context, in *EnterRequest, opts ...grpc.CallOption) (*EnterResponse, error)
+	// Exit marks the customer leaving the studio
+	Exit(ctx context.Context, in *ExitRequest, opts ...grpc.CallOption) (*Customer, error)
+	// Queue adds the customer to a queue of a ride
+	Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*CustomerState, error)
+	// UnQueue takes the customer out of their queue
+	UnQueue(ctx context.Context, in *UnQueueRequest, opts ...grpc.CallOption) (*CustomerState, error)
+	// GetCustomerState returns the current queue state of a customer
+	GetCustomerState(ctx context.Context, in *GetCustomerStateRequest, opts ...grpc.CallOption) (*CustomerState, error)
+}
+
+type customerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCustomerServiceClient(cc grpc.ClientConnInterface) CustomerServiceClient {
+	return &customerServiceClient{cc}
+}
+
+func (c *customerServiceClient) ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (*ListCustomersResponse, error) {
+	out := new(ListCustomersResponse)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/ListCustomers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Enter(ctx context.Context, in *EnterRequest, opts ...grpc.CallOption) (*EnterResponse, error) {
+	out := new(EnterResponse)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/Enter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Exit(ctx context.Context, in *ExitRequest, opts ...grpc.CallOption) (*Customer, error) {
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/Exit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*CustomerState, error) {
+	out := new(CustomerState)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/Queue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) UnQueue(ctx context.Context, in *UnQueueRequest, opts ...grpc.CallOption) (*CustomerState, error) {
+	out := new(CustomerState)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/UnQueue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) GetCustomerState(ctx context.Context, in *GetCustomerStateRequest, opts ...grpc.CallOption) (*CustomerState, error) {
+	out := new(CustomerState)
+	err := c.cc.Invoke(ctx, "/studios.v1.CustomerService/GetCustomerState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CustomerServiceServer is the server API for CustomerService service.
+// All implementations must embed UnimplementedCustomerServiceServer
+// for forward compatibility
+type CustomerServiceServer interface {
+	// ListCustomers returns all customers inside the studio
+	ListCustomers(context.Context, *ListCustomersRequest) (*ListCustomersResponse, error)
+	// Enter validates the entry ticket and lets the customer in
+	Enter(context.Context, *EnterRequest) (*EnterResponse, error)
+	// Exit marks the customer leaving the studio
+	Exit(context.Context, *ExitRequest) (*Customer, error)
+	// Queue adds the customer to a queue of a ride
+	Queue(context.Context, *QueueRequest) (*CustomerState, error)
+	// UnQueue takes the customer out of their queue
+	UnQueue(context.Context, *UnQueueRequest) (*CustomerState, error)
+	// GetCustomerState returns the current queue state of a customer
+	GetCustomerState(context.Context, *GetCustomerStateRequest) (*CustomerState, error)
+	mustEmbedUnimplementedCustomerServiceServer()
+}
+
+// UnimplementedCustomerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCustomerServiceServer struct {
+}
+
+func (UnimplementedCustomerServiceServer) ListCustomers(context.Context, *ListCustomersRequest) (*ListCustomersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCustomers not implemented")
+}
+func (UnimplementedCustomerServiceServer) Enter(context.Context, *EnterRequest) (*EnterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enter not implemented")
+}
+func (UnimplementedCustomerServiceServer) Exit(context.Context, *ExitRequest) (*Customer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exit not implemented")
+}
+func (UnimplementedCustomerServiceServer) Queue(context.Context, *QueueRequest) (*CustomerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Queue not implemented")
+}
+func (UnimplementedCustomerServiceServer) UnQueue(context.Context, *UnQueueRequest) (*CustomerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnQueue not implemented")
+}
+func (UnimplementedCustomerServiceServer) GetCustomerState(context.Context, *GetCustomerStateRequest) (*CustomerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCustomerState not implemented")
+}
+func (UnimplementedCustomerServiceServer) mustEmbedUnimplementedCustomerServiceServer() {}
+
+// UnsafeCustomerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CustomerServiceServer will
+// result in compilation errors.
+type UnsafeCustomerServiceServer interface {
+	mustEmbedUnimplementedCustomerServiceServer()
+}
+
+func RegisterCustomerServiceServer(s grpc.ServiceRegistrar, srv CustomerServiceServer) {
+	s.RegisterService(&_CustomerService_serviceDesc, srv)
+}
+
+func _CustomerService_ListCustomers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCustomersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).ListCustomers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/ListCustomers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).ListCustomers(ctx, req.(*ListCustomersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Enter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Enter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/Enter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Enter(ctx, req.(*EnterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Exit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Exit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/Exit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Exit(ctx, req.(*ExitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Queue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Queue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/Queue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Queue(ctx, req.(*QueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_UnQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).UnQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/UnQueue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).UnQueue(ctx, req.(*UnQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_GetCustomerState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).GetCustomerState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/studios.v1.CustomerService/GetCustomerState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).GetCustomerState(ctx, req.(*GetCustomerStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CustomerService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "studios.v1.CustomerService",
+	HandlerType: (*CustomerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCustomers",
+			Handler:    _CustomerService_ListCustomers_Handler,
+		},
+		{
+			MethodName: "Enter",
+			Handler:    _CustomerService_Enter_Handler,
+		},
+		{
+			MethodName: "Exit",
+			Handler:    _CustomerService_Exit_Handler,
+		},
+		{
+			MethodName: "Queue",
+			Handler:    _CustomerService_Queue_Handler,
+		},
+		{
+			MethodName: "UnQueue",
+			Handler:    _CustomerService_UnQueue_Handler,
+		},
+		{
+			MethodName: "GetCustomerState",
+			Handler:    _CustomerService_GetCustomerState_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "studios.proto",
+}
@@ -1,56 +1,60 @@
 package api
 
 import (
-	"log"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gitlab.com/therako/universal-studios/data/rides"
-	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
 )
 
 type Rides struct {
-	DAO rides.DAO
+	operations
+}
+
+type listQuery struct {
+	Zone           string `form:"zone"`
+	Tag            string `form:"tag"`
+	Accessibility  string `form:"accessibility"`
+	MaxThrillLevel uint   `form:"max_thrill_level"`
+	HeightCm       uint   `form:"height_cm"`
+	Age            uint   `form:"age"`
+	MaxWaitSecs    *uint  `form:"max_wait"`
+	Sort           string `form:"sort"`
 }
 
 // List returns a list of studio rides
 func (r Rides) List(c *gin.Context) {
-	rides, err := r.DAO.List()
+	var query listQuery
+	err := c.ShouldBindQuery(&query)
 	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
-		log.Println(err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
-	for idx, ride := range rides {
-		rideState, err := ridesEvents.GetCurrentState(r.DAO.DB, ride)
-		if err == nil {
-			waitTime := time.Duration(0)
-			if !rideState.EstimatedWaitTill.IsZero() {
-				waitTime = rideState.EstimatedWaitTill.Sub(time.Now())
-			}
-			if waitTime < 0 {
-				waitTime = 0
-			}
-			rides[idx].EstimatedWaitingTime = waitTime
-			rides[idx].InQueue = rideState.QueueCount
-		}
+	sort, desc := rideSort(query.Sort)
+	filtered, err := r.Services.Rides.List(c.Request.Context(), rides.Filter{
+		Zone:           query.Zone,
+		Tag:            query.Tag,
+		Accessibility:  query.Accessibility,
+		MaxThrillLevel: query.MaxThrillLevel,
+		HeightCm:       query.HeightCm,
+		Age:            query.Age,
+		MaxWait:        secondsP(query.MaxWaitSecs),
+		Sort:           sort,
+		Desc:           desc,
+	})
+	if err != nil {
+		handleError(c, err, "rides")
+		return
 	}
 
-	c.JSON(http.StatusOK, rides)
-}
-
-type AddForm struct {
-	Name         string `form:"name" binding:"required"`
-	Desc         string `form:"desc"`
-	RideTimeSecs uint   `form:"ride_time_secs" binding:"required"`
-	Capacity     uint   `form:"capacity" binding:"required"`
+	c.JSON(http.StatusOK, filtered)
 }
 
 // Add adds a new ride to the studio
 func (r Rides) Add(c *gin.Context) {
-	var input AddForm
+	var input AddRideRequest
 	err := c.Bind(&input)
 	if err != nil {
 		c.AbortWithStatusJSON(
@@ -60,17 +64,96 @@ func (r Rides) Add(c *gin.Context) {
 		return
 	}
 
-	err = r.DAO.Add(&rides.Ride{
-		Name:     input.Name,
-		Desc:     input.Desc,
-		Capacity: input.Capacity,
-		RideTime: time.Duration(input.RideTimeSecs) * time.Second,
-	})
+	_, err = r.addRide(input)
 	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
-		log.Println(err)
+		abortWithFailure(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "added"})
 }
+
+type updateForm struct {
+	ID uint `form:"id" binding:"required"`
+	UpdateRideRequest
+}
+
+// Update changes the details of a ride, only the values sent are updated
+func (r Rides) Update(c *gin.Context) {
+	var input updateForm
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ride, err := r.updateRide(input.ID, input.UpdateRideRequest)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "ride_id": ride.ID})
+}
+
+type statusForm struct {
+	ID   uint `form:"id" binding:"required"`
+	Down bool `form:"down"`
+}
+
+// Status marks a ride as down or back up and running
+func (r Rides) Status(c *gin.Context) {
+	var input statusForm
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ride, err := r.setRideStatus(c.Request.Context(), input.ID, input.Down)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "ride_id": ride.ID, "down": input.Down})
+}
+
+// Retire soft deletes a ride & un-queues the customers still in it's queues
+func (r Rides) Retire(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": "id must be a positive integer"})
+		return
+	}
+
+	ride, unQueued, err := r.retireRide(c.Request.Context(), uint(id))
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "retired", "ride_id": ride.ID, "unqueued": len(unQueued)})
+}
+
+type restoreForm struct {
+	ID uint `form:"id" binding:"required"`
+}
+
+// Restore brings a retired ride back into service
+func (r Rides) Restore(c *gin.Context) {
+	var input restoreForm
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ride, err := r.restoreRide(c.Request.Context(), input.ID)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "restored", "ride_id": ride.ID})
+}
@@ -48,10 +48,10 @@ func TestRideEndpoints(t *testing.T) {
 		customer := &customers.Customer{}
 		db.Create(&customer)
 		eventDAO := events.DAO{DB: db}
-		eventDAO.Add(&ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
-		eventDAO.Add(&ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
-		eventDAO.Add(&ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
-		eventDAO.Add(&ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
+		eventDAO.Add(context.Background(), &ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
+		eventDAO.Add(context.Background(), &ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
+		eventDAO.Add(context.Background(), &ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
+		eventDAO.Add(context.Background(), &ridesEvents.RideCustomerQueued{Ride: allRides[0], Customer: customer, From: time.Now(), To: time.Now().Add(10 * time.Minute)})
 		router := api.New(context.Background(), testConfig, db)
 
 		w := httptest.NewRecorder()
@@ -77,6 +77,9 @@ func TestRideAddEndpoints(t *testing.T) {
 		form.Add("desc", "Booooo")
 		form.Add("ride_time_secs", "300")
 		form.Add("capacity", "20")
+		form.Add("zone", "Marvel")
+		form.Add("thrill_level", "3")
+		form.Add("tags", "coaster")
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/ride/add", strings.NewReader(form.Encode()))
@@ -92,6 +95,9 @@ func TestRideAddEndpoints(t *testing.T) {
 		assert.Equal(t, "Booooo", ride.Desc)
 		assert.Equal(t, 300*time.Second, ride.RideTime)
 		assert.Equal(t, uint(20), ride.Capacity)
+		assert.Equal(t, "Marvel", ride.Zone)
+		assert.Equal(t, uint(3), ride.ThrillLevel)
+		assert.DeepEqual(t, models.StringList{"coaster"}, ride.Tags)
 	})
 
 	t.Run("error on missing input", func(t *testing.T) {
@@ -107,3 +113,152 @@ func TestRideAddEndpoints(t *testing.T) {
 		assert.Equal(t, `{"err":"Invalid request input. Expected atleast name, capacity \u0026 ride_time_secs"}`, w.Body.String())
 	})
 }
+
+func TestRideStatusEndpoints(t *testing.T) {
+	t.Run("expected to mark the ride as down", func(t *testing.T) {
+		db := testDB(t.Name())
+		ride := &rides.Ride{Model: models.Model{ID: 1401}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute}
+		db.Create(ride)
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "1401")
+		form.Add("down", "true")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ride/status", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"down":true,"ride_id":1401,"status":"updated"}`, w.Body.String())
+
+		state, err := ridesEvents.GetCurrentState(context.Background(), db, ride)
+		assert.NilError(t, err)
+		assert.Equal(t, true, state.Down)
+	})
+
+	t.Run("error when ride not found", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "1")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ride/status", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"err":"ride record not found"}`, w.Body.String())
+	})
+}
+
+func TestRideFilters(t *testing.T) {
+	db := testDB(t.Name())
+	allRides := []*rides.Ride{
+		{Model: models.Model{ID: 1501}, Name: "Dragon", Capacity: 1, RideTime: 10 * time.Minute, Zone: "Jurassic", MinHeightCm: 120, ThrillLevel: 5, Tags: models.StringList{"coaster", "dark"}},
+		{Model: models.Model{ID: 1502}, Name: "Carousel", Capacity: 10, RideTime: 5 * time.Minute, Zone: "Jurassic", ThrillLevel: 1, Tags: models.StringList{"family"}, Accessibility: models.StringList{"wheelchair"}},
+		{Model: models.Model{ID: 1503}, Name: "Mummy", Capacity: 10, RideTime: 5 * time.Minute, Zone: "Egypt", MinHeightCm: 110, ThrillLevel: 4, Tags: models.StringList{"coaster"}},
+		{Model: models.Model{ID: 1504}, Name: "Teacups", Capacity: 10, RideTime: 5 * time.Minute, Zone: "Egypt", ThrillLevel: 1, Tags: models.StringList{"50%_off", `say "hi"`}},
+	}
+	db.Create(&allRides)
+	ridesEvents.LogCustomerJoinedRideQueue(context.Background(), db, allRides[0], &customers.Customer{Model: models.Model{ID: 1}})
+	router := api.New(context.Background(), testConfig, db)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"Dragon", "Carousel", "Mummy", "Teacups"}},
+		{"?zone=Jurassic", []string{"Dragon", "Carousel"}},
+		{"?tag=coaster", []string{"Dragon", "Mummy"}},
+		{"?accessibility=wheelchair", []string{"Carousel"}},
+		{"?height_cm=115", []string{"Carousel", "Mummy", "Teacups"}},
+		{"?tag=" + url.QueryEscape("50%_off"), []string{"Teacups"}},
+		{"?tag=" + url.QueryEscape("%"), []string{}},
+		{"?tag=" + url.QueryEscape("co_ster"), []string{}},
+		{"?tag=" + url.QueryEscape(`say "hi"`), []string{"Teacups"}},
+		{"?tag=" + url.QueryEscape(`hi"`), []string{}},
+		{"?max_thrill_level=4&tag=coaster", []string{"Mummy"}},
+		{"?max_wait=60", []string{"Carousel", "Mummy", "Teacups"}},
+	}
+	for _, test := range tests {
+		t.Run("filter "+test.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/ride"+test.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, 200, w.Code)
+			var responseRides []*rides.Ride
+			err := json.Unmarshal(w.Body.Bytes(), &responseRides)
+			assert.NilError(t, err)
+			names := []string{}
+			for _, ride := range responseRides {
+				names = append(names, ride.Name)
+			}
+			assert.DeepEqual(t, test.expected, names)
+		})
+	}
+}
+
+func TestRideUpdateEndpoints(t *testing.T) {
+	t.Run("expected to update only the values sent", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&rides.Ride{Name: "DareDevil", Desc: "Booooo", Capacity: 20, RideTime: 5 * time.Minute, Tags: models.StringList{"old"}})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "1")
+		form.Add("zone", "Marvel")
+		form.Add("min_height_cm", "130")
+		form.Add("tags", "coaster")
+		form.Add("tags", "water")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ride/update", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"ride_id":1,"status":"updated"}`, w.Body.String())
+
+		ride := &rides.Ride{}
+		db.Table(rides.TableName).First(&ride)
+		assert.Equal(t, "DareDevil", ride.Name)
+		assert.Equal(t, uint(20), ride.Capacity)
+		assert.Equal(t, "Marvel", ride.Zone)
+		assert.Equal(t, uint(130), ride.MinHeightCm)
+		assert.DeepEqual(t, models.StringList{"coaster", "water"}, ride.Tags)
+	})
+
+	t.Run("error on zero capacity", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&rides.Ride{Name: "DareDevil", Capacity: 20, RideTime: 5 * time.Minute})
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "1")
+		form.Add("capacity", "0")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ride/update", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code)
+		assert.Equal(t, `{"err":"capacity \u0026 ride_time_secs can't be zero"}`, w.Body.String())
+	})
+
+	t.Run("error when ride not found", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("id", "1")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ride/update", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"err":"ride record not found"}`, w.Body.String())
+	})
+}
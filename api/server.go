@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitlab.com/therako/universal-studios/logging"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// workers tracks background goroutines, eg. the idempotency key cleanup, so shutting down can wait
+// for them to stop
+type workers struct {
+	sync.WaitGroup
+}
+
+// run runs f in a goroutine, f is expected to return once the ctx it was given is done
+func (w *workers) run(f func()) {
+	w.Add(1)
+	go func() {
+		defer w.Done()
+		f()
+	}()
+}
+
+// Server serves the HTTP & gRPC APIs, shutting down gracefully once it's context is done
+type Server struct {
+	HTTP *http.Server
+	// GRPC is nil when the gRPC API is disabled
+	GRPC *grpc.Server
+
+	config        Config
+	health        *health
+	workers       *workers
+	cancelWorkers context.CancelFunc
+}
+
+// NewServer returns a server of the HTTP router & gRPC services on the configured ports
+func NewServer(ctx context.Context, config Config, gormDB *gorm.DB) *Server {
+	workerCtx, cancelWorkers := context.WithCancel(ctx)
+	services := NewServices(config, gormDB)
+	s := &Server{
+		config:        config,
+		health:        newHealth(gormDB, services),
+		workers:       &workers{},
+		cancelWorkers: cancelWorkers,
+	}
+	if !config.GRPCDisabled {
+		s.GRPC = newGRPCServer(ctx, config, gormDB, services)
+	}
+	s.HTTP = &http.Server{
+		Addr:    fmt.Sprintf(":%d", config.HTTPPort),
+		Handler: newRouter(workerCtx, config, gormDB, services, s.workers, s.health),
+	}
+	return s
+}
+
+// Run serves both APIs till ctx is done or either fails, then shuts down waiting at most the
+// configured shutdown timeout
+func (s *Server) Run(ctx context.Context) error {
+	httpListener, err := net.Listen("tcp", s.HTTP.Addr)
+	if err != nil {
+		return err
+	}
+	var grpcListener net.Listener
+	if s.GRPC != nil {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", s.config.GRPCPort))
+		if err != nil {
+			httpListener.Close()
+			return err
+		}
+	}
+	return s.Serve(ctx, httpListener, grpcListener)
+}
+
+// Serve serves both APIs on the given listeners till ctx is done or either fails, then shuts down.
+// The gRPC listener is only used when the gRPC API is enabled.
+func (s *Server) Serve(ctx context.Context, httpListener, grpcListener net.Listener) error {
+	logger := logging.FromContext(ctx)
+	errs := make(chan error, 2)
+	go func() {
+		err := s.HTTP.Serve(httpListener)
+		if err != http.ErrServerClosed {
+			errs <- fmt.Errorf("http: %w", err)
+		}
+	}()
+	if s.GRPC != nil {
+		go func() {
+			err := s.GRPC.Serve(grpcListener)
+			if err != nil {
+				errs <- fmt.Errorf("grpc: %w", err)
+			}
+		}()
+		logger.Info("Serving gRPC", "addr", grpcListener.Addr().String())
+	}
+	logger.Info("Serving HTTP", "addr", httpListener.Addr().String())
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-errs:
+		logger.Error("Serving failed, shutting down", "err", serveErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.ShutdownTimeoutSecs)*time.Second)
+	defer cancel()
+	err := s.Shutdown(logging.WithContext(shutdownCtx, logger))
+	if serveErr != nil {
+		return serveErr
+	}
+	return err
+}
+
+// Shutdown drains the server, failing readiness checks, waiting for in-flight requests & calls like
+// queue writes to finish & then stopping the background workers. In-flight requests are cut off when
+// ctx is done first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	logger.Info("Shutting down")
+	s.health.drain()
+
+	err := s.HTTP.Shutdown(ctx)
+	if err != nil {
+		logger.Error("Failed to drain HTTP requests", "err", err)
+	}
+
+	if s.GRPC != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.GRPC.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logger.Error("Failed to drain gRPC calls", "err", ctx.Err())
+			s.GRPC.Stop()
+		}
+	}
+
+	s.cancelWorkers()
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Error("Background workers didn't stop", "err", ctx.Err())
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	logger.Info("Shut down")
+	return err
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gorm.io/gorm"
+)
+
+// Services are the ride & customer services shared by the HTTP routes & gRPC services, so both log
+// events on the same caches, clock & bus
+type Services struct {
+	Rides     *ridesEvents.RideService
+	Customers *customersEvents.CustomerService
+}
+
+// NewServices returns services on the DB & the config's clock with caches of their own sized by the config, customers
+// queue through the same ride service. Unsized caches, eg. in tests, turn caching off.
+func NewServices(config Config, gormDB *gorm.DB) Services {
+	r := ridesEvents.NewService(gormDB, ridesEvents.NewCache(config.RideCacheNumCounters, config.RideCacheMaxCost), config.Clock)
+	c := customersEvents.NewService(gormDB, customersEvents.NewCache(config.CustomerCacheNumCounters, config.CustomerCacheMaxCost), r.Clock, r)
+	return Services{Rides: r, Customers: c}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Tickets struct {
+	operations
+}
+
+// Add issues a new entry ticket
+func (r Tickets) Add(c *gin.Context) {
+	var input ticketDetails
+	err := c.Bind(&input)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ticket, err := r.addTicket(input)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "added", "code": ticket.Code})
+}
+
+type ticketURI struct {
+	Code string `uri:"code" binding:"required"`
+}
+
+// Get returns the ticket details along with how many times it was scanned
+func (r Tickets) Get(c *gin.Context) {
+	var uri ticketURI
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ticket, err := r.TicketDAO.GetByCode(uri.Code)
+	if err != nil {
+		handleError(c, err, "ticket")
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
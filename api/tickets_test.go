@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gotest.tools/v3/assert"
+)
+
+func TestTicketEndpoints(t *testing.T) {
+	t.Run("expected to add a day ticket by default", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("code", "abc")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ticket/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"code":"abc","status":"added"}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/ticket/abc", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		ticket := &tickets.Ticket{}
+		err := json.Unmarshal(w.Body.Bytes(), ticket)
+		assert.NilError(t, err)
+		assert.Equal(t, "day", ticket.Type)
+		assert.Equal(t, tickets.NoReEntry, ticket.ReEntry)
+		assert.Equal(t, 24*time.Hour, ticket.ValidTill.Sub(ticket.ValidFrom))
+		assert.NilError(t, ticket.ValidateEntry(time.Now()))
+	})
+
+	t.Run("expected to generate a code for multi day tickets", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("type", "multi_day")
+		form.Add("valid_from", "2020-12-20")
+		form.Add("valid_till", "2020-12-22")
+		form.Add("re_entry", "unlimited")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ticket/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		ticket := &tickets.Ticket{}
+		db.Table(tickets.TableName).First(ticket)
+		assert.Equal(t, 16, len(ticket.Code))
+		assert.Equal(t, 72*time.Hour, ticket.ValidTill.Sub(ticket.ValidFrom))
+		assert.Equal(t, tickets.UnlimitedReEntry, ticket.ReEntry)
+	})
+
+	t.Run("error on unknown re-entry policy", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+		form := url.Values{}
+		form.Add("re_entry", "sometimes")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ticket/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code)
+		assert.Equal(t, `{"err":"ticket Unknown re-entry policy"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,46 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gotest.tools/v3/assert"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracing.InMemory()
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3981}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	db.Create(&customers.Customer{Model: models.Model{ID: 398}})
+	router := api.New(context.Background(), authConfig, db)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	w := serveJSON(router, "POST", "/v1/customers/398/queue", `{"ride_id":3981}`, "X-API-Key", "operator-key", "traceparent", traceParent)
+	assert.Equal(t, 200, w.Code)
+
+	spans := exporter.GetSpans()
+	counts := map[string]int{}
+	for _, span := range spans {
+		counts[span.Name]++
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String(),
+			"expected %s to continue the caller's trace", span.Name)
+	}
+	assert.Equal(t, 1, counts["POST /v1/customers/:id/queue"])
+	assert.Assert(t, counts["customers.Cache.Get"] >= 1)
+	assert.Assert(t, counts["customers.aggregateState"] >= 1)
+	assert.Assert(t, counts["rides.Cache.Get"] >= 1)
+	assert.Assert(t, counts["rides.aggregateState"] >= 1)
+	assert.Assert(t, counts["events.DAO.EventFor"] >= 2)
+	// RideCustomerQueued & CustomerQueued
+	assert.Equal(t, 2, counts["events.DAO.Add"])
+}
@@ -0,0 +1,463 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const v1Prefix = "/v1"
+
+// route describes a v1 endpoint, used both to register it & to document it in the OpenAPI spec
+type route struct {
+	Method  string
+	Path    string
+	Summary string
+	Roles   []Role
+	// Query, Request & Response are zero values of the types bound & returned, nil when there are none
+	Query    interface{}
+	Request  interface{}
+	Response interface{}
+	// Status on success, defaults to 200
+	Status  int
+	Handler gin.HandlerFunc
+}
+
+// V1 serves the versioned JSON API
+type V1 struct {
+	operations
+}
+
+func (v V1) routes() []route {
+	return []route{
+		{Method: http.MethodGet, Path: "/rides", Summary: "List rides with their current waiting times",
+			Roles: []Role{RoleOperator, RoleGate, RoleGuest}, Query: RideListQuery{}, Response: []RideResponse{}, Handler: v.ListRides},
+		{Method: http.MethodPost, Path: "/rides", Summary: "Add a new ride", Status: http.StatusCreated,
+			Roles: []Role{RoleAdmin}, Request: AddRideRequest{}, Response: RideResponse{}, Handler: v.AddRide},
+		{Method: http.MethodPatch, Path: "/rides/:id", Summary: "Update the details of a ride",
+			Roles: []Role{RoleAdmin}, Request: UpdateRideRequest{}, Response: RideResponse{}, Handler: v.UpdateRide},
+		{Method: http.MethodDelete, Path: "/rides/:id", Summary: "Retire a ride, customers in it's queues are un-queued",
+			Roles: []Role{RoleAdmin}, Response: RetireRideResponse{}, Handler: v.RetireRide},
+		{Method: http.MethodPost, Path: "/rides/:id/restore", Summary: "Bring a retired ride back into service",
+			Roles: []Role{RoleAdmin}, Response: RideResponse{}, Handler: v.RestoreRide},
+		{Method: http.MethodPut, Path: "/rides/:id/status", Summary: "Mark a ride as down or back up",
+			Roles: []Role{RoleOperator}, Request: RideStatusRequest{}, Response: RideStatusResponse{}, Handler: v.RideStatus},
+
+		{Method: http.MethodPost, Path: "/tickets", Summary: "Issue an entry ticket", Status: http.StatusCreated,
+			Roles: []Role{RoleGate}, Request: AddTicketRequest{}, Response: TicketResponse{}, Handler: v.AddTicket},
+		{Method: http.MethodGet, Path: "/tickets/:code", Summary: "Get a ticket by its code",
+			Roles: []Role{RoleGate}, Response: TicketResponse{}, Handler: v.GetTicket},
+
+		{Method: http.MethodGet, Path: "/customers", Summary: "List customers a page at a time, by default the ones inside the studio",
+			Roles: []Role{RoleOperator, RoleGate}, Query: CustomerListQuery{}, Response: CustomerListResponse{}, Handler: v.ListCustomers},
+		{Method: http.MethodPost, Path: "/customers", Summary: "Let a customer in with their entry ticket",
+			Roles: []Role{RoleGate}, Request: EnterRequest{}, Response: EnterResponse{}, Handler: v.Enter},
+		{Method: http.MethodPost, Path: "/customers/:id/exit", Summary: "Mark a customer leaving the studio",
+			Roles: []Role{RoleGate}, Response: CustomerResponse{}, Handler: v.Exit},
+		{Method: http.MethodPost, Path: "/customers/:id/queue", Summary: "Queue a customer for a ride",
+			Roles: []Role{RoleOperator, RoleGuest}, Request: QueueRequest{}, Response: CustomerQueueResponse{}, Handler: v.Queue},
+		{Method: http.MethodPost, Path: "/customers/:id/unqueue", Summary: "Take a customer out of their queue",
+			Roles: []Role{RoleOperator, RoleGuest}, Response: CustomerQueueResponse{}, Handler: v.UnQueue},
+		{Method: http.MethodGet, Path: "/customers/:id/recommendations", Summary: "Rides a customer can go to next",
+			Roles: []Role{RoleOperator, RoleGuest}, Query: RecommendationsQuery{}, Response: []RideResponse{}, Handler: v.Recommendations},
+
+		{Method: http.MethodPost, Path: "/parties", Summary: "Group customers into a party", Status: http.StatusCreated,
+			Roles: []Role{RoleGate}, Request: AddPartyRequest{}, Response: PartyResponse{}, Handler: v.AddParty},
+		{Method: http.MethodGet, Path: "/parties/:id", Summary: "Get a party with its members",
+			Roles: []Role{RoleOperator, RoleGate}, Response: PartyResponse{}, Handler: v.GetParty},
+		{Method: http.MethodPost, Path: "/parties/:id/queue", Summary: "Queue all members of a party for a ride together",
+			Roles: []Role{RoleOperator, RoleGuest}, Request: PartyQueueRequest{}, Response: PartyResponse{}, Handler: v.QueueParty},
+	}
+}
+
+// registerV1 adds all v1 routes under /v1 along with the OpenAPI spec describing them
+func registerV1(router *gin.Engine, auth *authenticator, gormDB *gorm.DB, services Services) {
+	v := V1{operations: newOperations(gormDB, services, auth)}
+	routes := v.routes()
+
+	group := router.Group(v1Prefix)
+	for _, r := range routes {
+		group.Handle(r.Method, r.Path, auth.require(r.Roles...), r.Handler)
+	}
+
+	spec := openAPISpec(v1Prefix, routes)
+	group.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+}
+
+// deprecated marks a pre v1 route, pointing clients to the route replacing it
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+	}
+}
+
+func bindJSON(c *gin.Context, input interface{}) bool {
+	err := c.ShouldBindJSON(input)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func bindQuery(c *gin.Context, input interface{}) bool {
+	err := c.ShouldBindQuery(input)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "id must be a positive integer")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListRides returns studio rides matching the filters, a page at a time when a limit is given with
+// the next page's cursor in the X-Next-Cursor header
+func (v V1) ListRides(c *gin.Context) {
+	var query RideListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	offset, err := decodeCursor(query.Cursor)
+	if err != nil {
+		handleError(c, err, "rides")
+		return
+	}
+
+	page, next, err := v.Services.Rides.Page(c.Request.Context(), query.filter(), int(offset), query.Limit)
+	if err != nil {
+		handleError(c, err, "rides")
+		return
+	}
+
+	if next != 0 {
+		c.Header(NextCursorHeader, encodeCursor(uint(next)))
+	}
+	c.JSON(http.StatusOK, newRideResponses(page))
+}
+
+// AddRide adds a new ride to the studio
+func (v V1) AddRide(c *gin.Context) {
+	var input AddRideRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ride, err := v.addRide(input)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newRideResponse(ride))
+}
+
+// UpdateRide changes the details of a ride, only the values sent are updated
+func (v V1) UpdateRide(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var input UpdateRideRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ride, err := v.updateRide(id, input)
+	if err == nil {
+		err = v.withState(c.Request.Context(), ride)
+	}
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRideResponse(ride))
+}
+
+// RetireRide soft deletes a ride & un-queues the customers still in it's queues
+func (v V1) RetireRide(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	ride, unQueued, err := v.retireRide(c.Request.Context(), id)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	response := RetireRideResponse{RideID: ride.ID, RetiredAt: *ride.DeletedAt, UnQueuedCustomerIDs: []uint{}}
+	for _, customer := range unQueued {
+		response.UnQueuedCustomerIDs = append(response.UnQueuedCustomerIDs, customer.ID)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreRide brings a retired ride back into service
+func (v V1) RestoreRide(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	ride, err := v.restoreRide(c.Request.Context(), id)
+	if err == nil {
+		err = v.withState(c.Request.Context(), ride)
+	}
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRideResponse(ride))
+}
+
+// RideStatus marks a ride as down or back up and running
+func (v V1) RideStatus(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var input RideStatusRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ride, err := v.setRideStatus(c.Request.Context(), id, input.Down)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RideStatusResponse{RideID: ride.ID, Down: input.Down})
+}
+
+// AddTicket issues a new entry ticket
+func (v V1) AddTicket(c *gin.Context) {
+	var input AddTicketRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	details, err := input.details()
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	ticket, err := v.addTicket(details)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTicketResponse(ticket))
+}
+
+// GetTicket returns the ticket details along with how many times it was scanned
+func (v V1) GetTicket(c *gin.Context) {
+	ticket, err := v.TicketDAO.GetByCode(c.Param("code"))
+	if err != nil {
+		handleError(c, err, "ticket")
+		return
+	}
+
+	c.JSON(http.StatusOK, newTicketResponse(ticket))
+}
+
+// ListCustomers returns a page of customers matching the filters
+func (v V1) ListCustomers(c *gin.Context) {
+	var query CustomerListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filter, err := query.filter()
+	if err != nil {
+		handleError(c, err, "customers")
+		return
+	}
+
+	allCustomers, next, err := v.CustomerDAO.List(filter)
+	if err != nil {
+		handleError(c, err, "customers")
+		return
+	}
+
+	response := CustomerListResponse{Customers: make([]CustomerResponse, 0, len(allCustomers)), NextCursor: encodeCursor(next)}
+	for _, customer := range allCustomers {
+		response.Customers = append(response.Customers, newCustomerResponse(customer))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Enter validates the entry ticket and marks the customer entering the studio
+func (v V1) Enter(c *gin.Context) {
+	var input EnterRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	customer, reEntered, token, err := v.enter(input.Code)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, EnterResponse{CustomerID: customer.ID, ReEntered: reEntered, Token: token})
+}
+
+// Exit marks the customer leaving the studio
+func (v V1) Exit(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	customer, err := v.exit(id)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCustomerResponse(customer))
+}
+
+// customerQueueResponse responds the customer's queue state after queueing or leaving a queue
+func (v V1) customerQueueResponse(c *gin.Context, id uint) {
+	state, err := v.customerState(c.Request.Context(), id)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CustomerQueueResponse{
+		CustomerID: id,
+		Queueing:   state.Queueing,
+		RideID:     state.RideID,
+		QueueType:  string(state.QueueType),
+		From:       state.From,
+		To:         state.To,
+	})
+}
+
+// Queue marks the customer entering a queue for a ride
+func (v V1) Queue(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var input QueueRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	_, err := v.queue(c.Request.Context(), id, input.RideID, input.QueueType)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	v.customerQueueResponse(c, id)
+}
+
+// UnQueue marks the customer leaving a queue for a ride before ride + wait timer runs out
+func (v V1) UnQueue(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	_, err := v.unQueue(c.Request.Context(), id)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	v.customerQueueResponse(c, id)
+}
+
+// Recommendations returns the rides a customer can go to next, ranked by a strategy
+func (v V1) Recommendations(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var query RecommendationsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	recommended, err := v.recommend(c.Request.Context(), id, query.Strategy)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRideResponses(recommended))
+}
+
+// AddParty groups customers into a party so they can queue together
+func (v V1) AddParty(c *gin.Context) {
+	var input AddPartyRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	party, err := v.addParty(input.Name, input.MemberIDs)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPartyResponse(party))
+}
+
+// GetParty returns the party with all its members
+func (v V1) GetParty(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	party, err := v.PartyDAO.Get(id)
+	if err != nil {
+		handleError(c, err, "party")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPartyResponse(party))
+}
+
+// QueueParty marks all members of the party entering a queue for a ride together
+func (v V1) QueueParty(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var input PartyQueueRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	party, err := v.queueParty(c.Request.Context(), id, input.RideID)
+	if err != nil {
+		abortWithFailure(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPartyResponse(party))
+}
@@ -0,0 +1,336 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gotest.tools/v3/assert"
+)
+
+func serveJSON(router http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestV1Rides(t *testing.T) {
+	t.Run("expected to add a ride from a JSON body", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "POST", "/v1/rides", `{"name":"DareDevil","ride_time_secs":300,"capacity":20,"tags":["coaster"]}`)
+
+		assert.Equal(t, 201, w.Code)
+		response := api.RideResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "DareDevil", response.Name)
+		assert.Equal(t, uint(300), response.RideTimeSecs)
+		assert.DeepEqual(t, []string{"coaster"}, response.Tags)
+
+		ride, err := rides.DAO{DB: db}.Get(response.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, 5*time.Minute, ride.RideTime)
+	})
+
+	t.Run("error envelope when required fields are missing", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "POST", "/v1/rides", `{"name":"DareDevil"}`)
+
+		assert.Equal(t, 400, w.Code)
+		response := api.ErrorResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, api.CodeInvalidRequest, response.Error.Code)
+	})
+
+	t.Run("expected to list rides with their waits in seconds", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&rides.Ride{Model: models.Model{ID: 3301}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+		db.Create(&customers.Customer{Model: models.Model{ID: 330}})
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "POST", "/v1/customers/330/queue", `{"ride_id":3301}`)
+		assert.Equal(t, 200, w.Code)
+
+		w = serveJSON(router, "GET", "/v1/rides", "")
+		assert.Equal(t, 200, w.Code)
+		response := []api.RideResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, len(response))
+		assert.Equal(t, uint(1), response[0].InQueue)
+		assert.Assert(t, response[0].WaitingTimeSecs > 590 && response[0].WaitingTimeSecs <= 600)
+		assert.Equal(t, 0, len(response[0].Queues))
+	})
+
+	t.Run("expected to update a ride & its status by id", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&rides.Ride{Model: models.Model{ID: 3302}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "PATCH", "/v1/rides/3302", `{"name":"Dragon","capacity":4}`)
+		assert.Equal(t, 200, w.Code)
+		ride, _ := rides.DAO{DB: db}.Get(3302)
+		assert.Equal(t, "Dragon", ride.Name)
+		assert.Equal(t, uint(4), ride.Capacity)
+		assert.Equal(t, 10*time.Minute, ride.RideTime)
+
+		w = serveJSON(router, "PUT", "/v1/rides/3302/status", `{"down":true}`)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"ride_id":3302,"down":true}`, w.Body.String())
+	})
+
+	t.Run("rejects unknown queue types", func(t *testing.T) {
+		db := testDB(t.Name())
+		db.Create(&rides.Ride{Model: models.Model{ID: 3304}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "POST", "/v1/rides", `{"name":"DareDevil","capacity":4,"ride_time_secs":60,"queue_types":["single_rider","express"]}`)
+		assert.Equal(t, 400, w.Code)
+		assert.Equal(t, `{"error":{"code":"unknown_queue_type","message":"Unknown queue type express"}}`, w.Body.String())
+
+		w = serveJSON(router, "PATCH", "/v1/rides/3304", `{"queue_types":["express"]}`)
+		assert.Equal(t, 400, w.Code)
+		ride, _ := rides.DAO{DB: db}.Get(3304)
+		assert.Equal(t, 0, len(ride.QueueTypes))
+		var count int64
+		db.Model(&rides.Ride{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("error envelope when ride doesn't exist", func(t *testing.T) {
+		db := testDB(t.Name())
+		router := api.New(context.Background(), testConfig, db)
+
+		w := serveJSON(router, "PATCH", "/v1/rides/404", `{"name":"Dragon"}`)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, `{"error":{"code":"not_found","message":"ride record not found"}}`, w.Body.String())
+	})
+}
+
+func TestV1CustomerFlow(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3303}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	// Entered customer gets the next id, keeping it apart from customers cached by other tests
+	db.Create(&customers.Customer{Model: models.Model{ID: 339}})
+	router := api.New(context.Background(), testConfig, db)
+
+	w := serveJSON(router, "POST", "/v1/tickets", `{"code":"v1-abc"}`)
+	assert.Equal(t, 201, w.Code)
+	ticket := api.TicketResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &ticket))
+	assert.Equal(t, "day", ticket.Type)
+	assert.Equal(t, string(tickets.NoReEntry), ticket.ReEntry)
+
+	w = serveJSON(router, "POST", "/v1/customers", `{"code":"v1-abc"}`)
+	assert.Equal(t, 200, w.Code)
+	entered := api.EnterResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &entered))
+	assert.Equal(t, uint(340), entered.CustomerID)
+	assert.Equal(t, false, entered.ReEntered)
+	assert.Equal(t, "", entered.Token)
+
+	id := "/v1/customers/" + jsonNumber(entered.CustomerID)
+	w = serveJSON(router, "POST", id+"/queue", `{"ride_id":3303}`)
+	assert.Equal(t, 200, w.Code)
+	queued := api.CustomerQueueResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &queued))
+	assert.Equal(t, true, queued.Queueing)
+	assert.Equal(t, uint(3303), queued.RideID)
+	assert.Equal(t, "standby", queued.QueueType)
+
+	w = serveJSON(router, "POST", id+"/unqueue", "")
+	assert.Equal(t, 200, w.Code)
+	unqueued := api.CustomerQueueResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &unqueued))
+	assert.Equal(t, false, unqueued.Queueing)
+
+	w = serveJSON(router, "POST", id+"/exit", "")
+	assert.Equal(t, 200, w.Code)
+	exited := api.CustomerResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &exited))
+	assert.Assert(t, exited.ExitAt != nil)
+
+	w = serveJSON(router, "POST", "/v1/customers/abc/exit", "")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":{"code":"invalid_request","message":"id must be a positive integer"}}`, w.Body.String())
+}
+
+func TestV1RetireRide(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3901}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute})
+	db.Create(&customers.Customer{Model: models.Model{ID: 390}})
+	db.Create(&customers.Customer{Model: models.Model{ID: 391}})
+	router := api.New(context.Background(), testConfig, db)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/390/queue", `{"ride_id":3901}`).Code)
+
+	w := serveJSON(router, "DELETE", "/v1/rides/3901", "")
+	assert.Equal(t, 200, w.Code)
+	retired := api.RetireRideResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &retired))
+	assert.DeepEqual(t, []uint{390}, retired.UnQueuedCustomerIDs)
+
+	w = serveJSON(router, "POST", "/v1/customers/390/unqueue", "")
+	assert.Equal(t, 409, w.Code, "expected the customer to be un-queued already")
+	w = serveJSON(router, "POST", "/v1/customers/391/queue", `{"ride_id":3901}`)
+	assert.Equal(t, 410, w.Code)
+	assert.Equal(t, `{"error":{"code":"ride_retired","message":"queue Ride is retired"}}`, w.Body.String())
+	assert.Equal(t, 409, serveJSON(router, "DELETE", "/v1/rides/3901", "").Code)
+
+	list := []api.RideResponse{}
+	assert.NilError(t, json.Unmarshal(serveJSON(router, "GET", "/v1/rides", "").Body.Bytes(), &list))
+	assert.Equal(t, 0, len(list))
+	assert.NilError(t, json.Unmarshal(serveJSON(router, "GET", "/v1/rides?retired=true", "").Body.Bytes(), &list))
+	assert.Equal(t, 1, len(list))
+	assert.Assert(t, list[0].RetiredAt != nil)
+
+	w = serveJSON(router, "POST", "/v1/rides/3901/restore", "")
+	assert.Equal(t, 200, w.Code)
+	restored := api.RideResponse{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Assert(t, restored.RetiredAt == nil)
+	assert.Equal(t, 200, serveJSON(router, "POST", "/v1/customers/391/queue", `{"ride_id":3901}`).Code)
+	assert.Equal(t, 409, serveJSON(router, "POST", "/v1/rides/3901/restore", "").Code)
+
+	t.Run("expected the older routes to retire & restore", func(t *testing.T) {
+		w := serveJSON(router, "DELETE", "/ride/3901", "")
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"ride_id":3901,"status":"retired","unqueued":1}`, w.Body.String())
+
+		w = postForm(router, "/ride/restore", url.Values{"id": {"3901"}})
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `{"ride_id":3901,"status":"restored"}`, w.Body.String())
+	})
+}
+
+func jsonNumber(id uint) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+func TestV1Auth(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), authConfig, db)
+
+	w := serveJSON(router, "GET", "/v1/customers", "")
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `{"error":{"code":"unauthenticated","message":"Missing or invalid credentials"}}`, w.Body.String())
+
+	w = serveJSON(router, "POST", "/v1/rides", `{}`, "X-API-Key", "gate-key")
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, `{"error":{"code":"forbidden","message":"Not allowed to access this route"}}`, w.Body.String())
+
+	// Routes before v1 keep their error format
+	w = serveJSON(router, "GET", "/customer", "")
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `{"err":"Missing or invalid credentials"}`, w.Body.String())
+}
+
+func TestDeprecatedRoutes(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), testConfig, db)
+
+	w := serveJSON(router, "GET", "/ride", "")
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/rides>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = serveJSON(router, "GET", "/v1/rides", "")
+	assert.Equal(t, "", w.Header().Get("Deprecation"))
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	db := testDB(t.Name())
+	router := api.New(context.Background(), authConfig, db)
+
+	// The spec is public, even with auth enabled
+	w := serveJSON(router, "GET", "/v1/openapi.json", "")
+	assert.Equal(t, 200, w.Code)
+
+	spec := struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string               `json:"required"`
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+
+	for path, methods := range map[string][]string{
+		"/v1/rides":                          {"get", "post"},
+		"/v1/rides/{id}":                     {"patch", "delete"},
+		"/v1/rides/{id}/restore":             {"post"},
+		"/v1/rides/{id}/status":              {"put"},
+		"/v1/tickets":                        {"post"},
+		"/v1/tickets/{code}":                 {"get"},
+		"/v1/customers":                      {"get", "post"},
+		"/v1/customers/{id}/exit":            {"post"},
+		"/v1/customers/{id}/queue":           {"post"},
+		"/v1/customers/{id}/unqueue":         {"post"},
+		"/v1/customers/{id}/recommendations": {"get"},
+		"/v1/parties":                        {"post"},
+		"/v1/parties/{id}":                   {"get"},
+		"/v1/parties/{id}/queue":             {"post"},
+	} {
+		for _, method := range methods {
+			_, ok := spec.Paths[path][method]
+			assert.Assert(t, ok, "%s %s missing from spec", method, path)
+		}
+	}
+
+	_, ok := spec.Paths["/v1/rides"]["post"]["requestBody"]
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, []string{"name", "ride_time_secs", "capacity"}, spec.Components.Schemas["AddRideRequest"].Required)
+	_, ok = spec.Components.Schemas["ErrorResponse"].Properties["error"]
+	assert.Assert(t, ok)
+}
+
+func TestV1WritesInvalidateCachedStates(t *testing.T) {
+	db := testDB(t.Name())
+	db.Create(&rides.Ride{Model: models.Model{ID: 3311}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute})
+	db.Create(&customers.Customer{Model: models.Model{ID: 331}})
+	router := api.New(context.Background(), testConfig, db)
+	inQueue := func() uint {
+		w := serveJSON(router, "GET", "/v1/rides", "")
+		assert.Equal(t, 200, w.Code)
+		response := []api.RideResponse{}
+		assert.NilError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response[0].InQueue
+	}
+
+	// Caches the ride's empty state
+	assert.Equal(t, uint(0), inQueue())
+
+	w := serveJSON(router, "POST", "/v1/customers/331/queue", `{"ride_id":3311}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, uint(1), inQueue(), "expected the queue write to be seen on the next GET")
+
+	w = serveJSON(router, "POST", "/v1/customers/331/queue", `{"ride_id":3311}`)
+	assert.Equal(t, 409, w.Code, "expected the customer's cached state to have been replaced")
+
+	w = serveJSON(router, "POST", "/v1/customers/331/unqueue", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, uint(0), inQueue())
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/parties"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/data/tickets"
+)
+
+// RideListQuery filters the list of rides
+type RideListQuery struct {
+	Zone           string `form:"zone" json:"zone"`
+	Tag            string `form:"tag" json:"tag"`
+	Accessibility  string `form:"accessibility" json:"accessibility"`
+	MaxThrillLevel uint   `form:"max_thrill_level" json:"max_thrill_level"`
+	HeightCm       uint   `form:"height_cm" json:"height_cm"`
+	Age            uint   `form:"age" json:"age"`
+	MaxWaitSecs    *uint  `form:"max_wait_secs" json:"max_wait_secs"`
+	// Sort is name or wait, prefixed with - for descending
+	Sort string `form:"sort" json:"sort"`
+	// Retired lists the retired rides instead of the ones in service
+	Retired bool `form:"retired" json:"retired"`
+	// Cursor is the X-Next-Cursor of the previous page
+	Cursor string `form:"cursor" json:"cursor"`
+	// Limit pages through the rides, all of them are listed by default
+	Limit int `form:"limit" json:"limit"`
+}
+
+func (q RideListQuery) filter() rides.Filter {
+	sort, desc := rideSort(q.Sort)
+	return rides.Filter{
+		Zone:           q.Zone,
+		Tag:            q.Tag,
+		Accessibility:  q.Accessibility,
+		MaxThrillLevel: q.MaxThrillLevel,
+		HeightCm:       q.HeightCm,
+		Age:            q.Age,
+		MaxWait:        secondsP(q.MaxWaitSecs),
+		Retired:        q.Retired,
+		Sort:           sort,
+		Desc:           desc,
+	}
+}
+
+// AddRideRequest adds a new ride to the studio
+type AddRideRequest struct {
+	Name               string   `form:"name" json:"name" binding:"required"`
+	Desc               string   `form:"desc" json:"desc"`
+	RideTimeSecs       uint     `form:"ride_time_secs" json:"ride_time_secs" binding:"required"`
+	Capacity           uint     `form:"capacity" json:"capacity" binding:"required"`
+	Zone               string   `form:"zone" json:"zone"`
+	MinHeightCm        uint     `form:"min_height_cm" json:"min_height_cm"`
+	MinAge             uint     `form:"min_age" json:"min_age"`
+	ThrillLevel        uint     `form:"thrill_level" json:"thrill_level"`
+	Accessibility      []string `form:"accessibility" json:"accessibility"`
+	Tags               []string `form:"tags" json:"tags"`
+	QueueTypes         []string `form:"queue_types" json:"queue_types"`
+	PriorityMergeRatio uint     `form:"priority_merge_ratio" json:"priority_merge_ratio"`
+}
+
+// UpdateRideRequest changes the details of a ride, only the values sent are updated
+type UpdateRideRequest struct {
+	Name               *string  `form:"name" json:"name"`
+	Desc               *string  `form:"desc" json:"desc"`
+	RideTimeSecs       *uint    `form:"ride_time_secs" json:"ride_time_secs"`
+	Capacity           *uint    `form:"capacity" json:"capacity"`
+	Zone               *string  `form:"zone" json:"zone"`
+	MinHeightCm        *uint    `form:"min_height_cm" json:"min_height_cm"`
+	MinAge             *uint    `form:"min_age" json:"min_age"`
+	ThrillLevel        *uint    `form:"thrill_level" json:"thrill_level"`
+	Accessibility      []string `form:"accessibility" json:"accessibility"`
+	Tags               []string `form:"tags" json:"tags"`
+	QueueTypes         []string `form:"queue_types" json:"queue_types"`
+	PriorityMergeRatio *uint    `form:"priority_merge_ratio" json:"priority_merge_ratio"`
+}
+
+// RideStatusRequest marks a ride as down or back up
+type RideStatusRequest struct {
+	Down bool `json:"down"`
+}
+
+// QueueResponse is the current state of a single queue of a ride
+type QueueResponse struct {
+	WaitingTimeSecs uint `json:"waiting_time_secs"`
+	InQueue         uint `json:"in_queue"`
+}
+
+// RideResponse is a ride along with it's current waiting times
+type RideResponse struct {
+	ID                 uint     `json:"id"`
+	Name               string   `json:"name"`
+	Desc               string   `json:"desc"`
+	RideTimeSecs       uint     `json:"ride_time_secs"`
+	Capacity           uint     `json:"capacity"`
+	Zone               string   `json:"zone"`
+	MinHeightCm        uint     `json:"min_height_cm"`
+	MinAge             uint     `json:"min_age"`
+	ThrillLevel        uint     `json:"thrill_level"`
+	Accessibility      []string `json:"accessibility"`
+	Tags               []string `json:"tags"`
+	QueueTypes         []string `json:"queue_types"`
+	PriorityMergeRatio uint     `json:"priority_merge_ratio"`
+	WaitingTimeSecs    uint     `json:"waiting_time_secs"`
+	InQueue            uint     `json:"in_queue"`
+	// Queues has the state of each queue type the ride has besides standby
+	Queues map[string]QueueResponse `json:"queues"`
+	// RetiredAt is set once the ride is taken out of service
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+func newRideResponse(ride *rides.Ride) RideResponse {
+	queues := map[string]QueueResponse{}
+	for queueType, wait := range ride.QueueWaitingTimes {
+		queues[queueType] = QueueResponse{
+			WaitingTimeSecs: uint(wait.Seconds()),
+			InQueue:         ride.QueueInCounts[queueType],
+		}
+	}
+
+	return RideResponse{
+		ID:                 ride.ID,
+		Name:               ride.Name,
+		Desc:               ride.Desc,
+		RideTimeSecs:       uint(ride.RideTime.Seconds()),
+		Capacity:           ride.Capacity,
+		Zone:               ride.Zone,
+		MinHeightCm:        ride.MinHeightCm,
+		MinAge:             ride.MinAge,
+		ThrillLevel:        ride.ThrillLevel,
+		Accessibility:      nonNil(ride.Accessibility),
+		Tags:               nonNil(ride.Tags),
+		QueueTypes:         nonNil(ride.QueueTypes),
+		PriorityMergeRatio: ride.PriorityMergeRatio,
+		WaitingTimeSecs:    uint(ride.EstimatedWaitingTime.Seconds()),
+		InQueue:            ride.InQueue,
+		Queues:             queues,
+		RetiredAt:          ride.DeletedAt,
+	}
+}
+
+func newRideResponses(allRides []*rides.Ride) []RideResponse {
+	responses := make([]RideResponse, 0, len(allRides))
+	for _, ride := range allRides {
+		responses = append(responses, newRideResponse(ride))
+	}
+	return responses
+}
+
+// RetireRideResponse is a retired ride along with the customers taken out of it's queues
+type RetireRideResponse struct {
+	RideID              uint      `json:"ride_id"`
+	RetiredAt           time.Time `json:"retired_at"`
+	UnQueuedCustomerIDs []uint    `json:"unqueued_customer_ids"`
+}
+
+// RideStatusResponse is the status of a ride after an update
+type RideStatusResponse struct {
+	RideID uint `json:"ride_id"`
+	Down   bool `json:"down"`
+}
+
+// AddTicketRequest issues a new entry ticket, defaults to a day ticket for today
+type AddTicketRequest struct {
+	Code string `json:"code"`
+	Type string `json:"type"`
+	// Dates the ticket is valid on in YYYY-MM-DD, both inclusive
+	ValidFrom string `json:"valid_from"`
+	ValidTill string `json:"valid_till"`
+	ReEntry   string `json:"re_entry"`
+}
+
+// details parses the dates, left zero when not given so they're defaulted like the older route
+func (r AddTicketRequest) details() (ticketDetails, error) {
+	details := ticketDetails{Code: r.Code, Type: r.Type, ReEntry: r.ReEntry}
+	var err error
+	if r.ValidFrom != "" {
+		details.ValidFrom, err = time.ParseInLocation("2006-01-02", r.ValidFrom, time.Local)
+		if err != nil {
+			return ticketDetails{}, errInvalidValidFrom
+		}
+	}
+	if r.ValidTill != "" {
+		details.ValidTill, err = time.ParseInLocation("2006-01-02", r.ValidTill, time.Local)
+		if err != nil {
+			return ticketDetails{}, errInvalidValidTill
+		}
+	}
+	return details, nil
+}
+
+// TicketResponse is an entry ticket along with how many times it was scanned
+type TicketResponse struct {
+	Code       string     `json:"code"`
+	Type       string     `json:"type"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTill  time.Time  `json:"valid_till"`
+	ReEntry    string     `json:"re_entry"`
+	ScanCount  uint       `json:"scan_count"`
+	LastScanAt *time.Time `json:"last_scan_at"`
+}
+
+func newTicketResponse(ticket *tickets.Ticket) TicketResponse {
+	return TicketResponse{
+		Code:       ticket.Code,
+		Type:       ticket.Type,
+		ValidFrom:  ticket.ValidFrom,
+		ValidTill:  ticket.ValidTill,
+		ReEntry:    string(ticket.ReEntry),
+		ScanCount:  ticket.ScanCount,
+		LastScanAt: ticket.LastScanAt,
+	}
+}
+
+// CustomerResponse is a customer of the studio
+type CustomerResponse struct {
+	ID        uint       `json:"id"`
+	EnteredAt time.Time  `json:"entered_at"`
+	ExitAt    *time.Time `json:"exit_at"`
+	PartyID   *uint      `json:"party_id"`
+}
+
+func newCustomerResponse(customer *customers.Customer) CustomerResponse {
+	return CustomerResponse{
+		ID:        customer.ID,
+		EnteredAt: customer.CreatedAt,
+		ExitAt:    customer.ExitAt,
+		PartyID:   customer.PartyID,
+	}
+}
+
+// CustomerListQuery filters & pages through customers, by default the ones inside the studio
+type CustomerListQuery struct {
+	// Status is in_park, exited or all
+	Status   string `form:"status" json:"status"`
+	Queueing *bool  `form:"queueing" json:"queueing"`
+	// CreatedFrom & CreatedTill in RFC 3339, customers are created on their first entry
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00" json:"created_from"`
+	CreatedTill *time.Time `form:"created_till" time_format:"2006-01-02T15:04:05Z07:00" json:"created_till"`
+	// Cursor is the next_cursor of the previous page
+	Cursor string `form:"cursor" json:"cursor"`
+	// Limit defaults to 100, at most 1000
+	Limit int `form:"limit" json:"limit"`
+}
+
+func (q CustomerListQuery) filter() (customers.Filter, error) {
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return customers.Filter{}, err
+	}
+	return customers.Filter{
+		Status:      customers.Status(q.Status),
+		Queueing:    q.Queueing,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTill: q.CreatedTill,
+		After:       after,
+		PageSize:    q.Limit,
+	}, nil
+}
+
+// CustomerListResponse is a page of customers, next_cursor is empty on the last page
+type CustomerListResponse struct {
+	Customers  []CustomerResponse `json:"customers"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// EnterRequest lets a customer in using their entry ticket
+type EnterRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// EnterResponse is the customer who entered, token is set when guest tokens are enabled
+type EnterResponse struct {
+	CustomerID uint   `json:"customer_id"`
+	ReEntered  bool   `json:"re_entered"`
+	Token      string `json:"token,omitempty"`
+}
+
+// QueueRequest queues a customer for a ride
+type QueueRequest struct {
+	RideID uint `json:"ride_id" binding:"required"`
+	// QueueType defaults to standby
+	QueueType string `json:"queue_type"`
+}
+
+// CustomerQueueResponse is the queue state of a customer
+type CustomerQueueResponse struct {
+	CustomerID uint      `json:"customer_id"`
+	Queueing   bool      `json:"queueing"`
+	RideID     uint      `json:"ride_id"`
+	QueueType  string    `json:"queue_type"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+}
+
+// RecommendationsQuery picks the strategy to rank rides with
+type RecommendationsQuery struct {
+	Strategy string `form:"strategy" json:"strategy"`
+}
+
+// AddPartyRequest groups customers into a party
+type AddPartyRequest struct {
+	Name      string `json:"name"`
+	MemberIDs []uint `json:"member_ids" binding:"required"`
+}
+
+// PartyQueueRequest queues all members of a party for a ride, parties queue in standby
+type PartyQueueRequest struct {
+	RideID uint `json:"ride_id" binding:"required"`
+}
+
+// PartyResponse is a party with it's members
+type PartyResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	MemberIDs []uint `json:"member_ids"`
+}
+
+func newPartyResponse(party *parties.Party) PartyResponse {
+	memberIDs := make([]uint, 0, len(party.Members))
+	for _, member := range party.Members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+	return PartyResponse{ID: party.ID, Name: party.Name, MemberIDs: memberIDs}
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,146 @@
+// Package cli runs the studio's commands, serving the APIs & the operations tasks like migrating,
+// replaying states & moving events around which otherwise need the HTTP API or raw SQL.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"gitlab.com/therako/universal-studios/api"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gorm.io/gorm"
+)
+
+// Errors
+var (
+	ErrUnknownCommand = errors.New("Unknown command")
+	ErrUsage          = errors.New("Invalid usage")
+)
+
+// Env is what the commands run with, set up by main from the config
+type Env struct {
+	Config api.Config
+	DB     *gorm.DB
+	// In is read by commands taking input without a file, eg. import-events
+	In io.Reader
+	// Out is where commands write their results
+	Out io.Writer
+}
+
+// services returns uncached ride & customer services on the env's DB & the configured clock, commands
+// don't run long enough for caches to pay off
+func services(env Env) api.Services {
+	rides := ridesEvents.NewService(env.DB, nil, env.Config.Clock)
+	return api.Services{Rides: rides, Customers: customersEvents.NewService(env.DB, nil, env.Config.Clock, rides)}
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, env Env, args []string) error
+}
+
+// commands in the order they're listed in the usage
+var commands = []command{
+	{"serve", "", "serve the HTTP & gRPC APIs (default)", serve},
+	{"migrate", "[up [-to version] | down [-steps n] | status]", "apply, roll back or list the DB migrations", migrate},
+	{"replay", "-ride id | -customer id", "rebuild a ride's or customer's state from it's events & print it", replay},
+	{"add-ride", "-name name -capacity n -ride-time-secs n [...]", "add a new ride", addRide},
+	{"close-ride", "-id id [-retire]", "mark a ride as down, or retire it un-queueing it's customers", closeRide},
+	{"export-events", "[-format jsonl|columnar] [-aggregate root] [-name name] [-from time] [-till time] [-out file]", "write the events, as JSON lines by default", exportEvents},
+	{"import-events", "[-format jsonl|columnar] [-in file]", "validate & append the events of an export", importEvents},
+	{"snapshot", "[-out file]", "write the current state of every ride as JSON", snapshot},
+	{"simulate", "[-seed n] [-duration d] [-arrivals-per-hour n] [-patience d] [-json]", "simulate a day at the park in memory & report how accurate the quoted waits were", simulate},
+}
+
+// DefaultCommand is run when no command is given
+const DefaultCommand = "serve"
+
+// Name returns the name of the command the args run
+func Name(args []string) string {
+	if len(args) == 0 {
+		return DefaultCommand
+	}
+	return args[0]
+}
+
+// Run runs the command named by the first arg with the rest of the args, serving when there are none
+func Run(ctx context.Context, env Env, args []string) error {
+	name := Name(args)
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		Usage(env.Out)
+		return nil
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			err := c.run(ctx, env, args)
+			if err == flag.ErrHelp {
+				return nil
+			}
+			return err
+		}
+	}
+	Usage(env.Out)
+	return fmt.Errorf("%w %q", ErrUnknownCommand, name)
+}
+
+// Usage writes the list of commands to out
+func Usage(out io.Writer) {
+	fmt.Fprintln(out, "Usage: universal-studios [command] [flags]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", c.name, c.args, c.summary)
+	}
+	w.Flush()
+}
+
+// newFlags returns the flag set of a command, printing it's usage to the env's output
+func newFlags(env Env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(env.Out)
+	return flags
+}
+
+// printJSON writes v as indented JSON
+func printJSON(out io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// output returns the file at path to write to, the env's output when path is empty
+func output(env Env, path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{env.Out}, nil
+	}
+	return os.Create(path)
+}
+
+// input returns the file at path to read from, the env's input when path is empty
+func input(env Env, path string) (io.ReadCloser, error) {
+	if path == "" {
+		return ioutil.NopCloser(env.In), nil
+	}
+	return os.Open(path)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,251 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gitlab.com/therako/universal-studios/cli"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/migrations"
+	"gitlab.com/therako/universal-studios/data/rides"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gotest.tools/v3/assert"
+)
+
+func testEnv(t *testing.T, name string) (cli.Env, *bytes.Buffer) {
+	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
+	sqlDB, err := gormDB.DB()
+	assert.NilError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Every connection opens a new in-memory DB
+	_, err = migrations.New(gormDB).Up(context.Background(), 0)
+	assert.NilError(t, err)
+
+	out := &bytes.Buffer{}
+	return cli.Env{DB: gormDB, In: &bytes.Buffer{}, Out: out}, out
+}
+
+// uncached returns a customer service on the env's DB & clock without caches, like the commands use
+func uncached(env cli.Env) *customersEvents.CustomerService {
+	return customersEvents.NewService(env.DB, nil, env.Config.Clock, ridesEvents.NewService(env.DB, nil, env.Config.Clock))
+}
+
+func run(env cli.Env, args ...string) error {
+	return cli.Run(context.Background(), env, args)
+}
+
+func TestRideCommands(t *testing.T) {
+	env, out := testEnv(t, t.Name())
+
+	err := run(env, "add-ride", "-name", "Jurassic Park", "-capacity", "10", "-ride-time-secs", "60", "-tags", "water, dinos")
+	assert.NilError(t, err)
+	ride := &rides.Ride{}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), ride))
+	assert.Equal(t, "Jurassic Park", ride.Name)
+	assert.DeepEqual(t, []string{"water", "dinos"}, []string(ride.Tags))
+
+	out.Reset()
+	err = run(env, "close-ride", "-id", fmt.Sprint(ride.ID))
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprintf("Marked ride %d as down\n", ride.ID), out.String())
+
+	out.Reset()
+	err = run(env, "replay", "-ride", fmt.Sprint(ride.ID))
+	assert.NilError(t, err)
+	replayed := struct {
+		Ride  rides.Ride `json:"ride"`
+		State struct {
+			Down bool `json:"down"`
+		} `json:"state"`
+	}{}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &replayed))
+	assert.Equal(t, ride.ID, replayed.Ride.ID)
+	assert.Equal(t, true, replayed.State.Down)
+
+	out.Reset()
+	err = run(env, "close-ride", "-id", fmt.Sprint(ride.ID), "-retire")
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprintf("Retired ride %d, un-queued 0 customers\n", ride.ID), out.String())
+
+	t.Run("expected required flags to be checked", func(t *testing.T) {
+		err := run(env, "add-ride", "-name", "Jaws")
+		assert.Assert(t, errors.Is(err, cli.ErrUsage))
+		err = run(env, "close-ride")
+		assert.Assert(t, errors.Is(err, cli.ErrUsage))
+		err = run(env, "replay", "-ride", "1", "-customer", "1")
+		assert.Assert(t, errors.Is(err, cli.ErrUsage))
+	})
+}
+
+func TestReplayCustomer(t *testing.T) {
+	ctx := context.Background()
+	env, out := testEnv(t, t.Name())
+	// Long before now, so the customer is only still queueing if the commands tell the time on it
+	at := time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+	env.Config.Clock = clockwork.NewFakeClockAt(at)
+	ride := &rides.Ride{Name: "Mummy", Capacity: 2, RideTime: 60e9}
+	assert.NilError(t, rides.DAO{DB: env.DB}.Add(ride))
+	customer := &customers.Customer{}
+	assert.NilError(t, env.DB.Create(customer).Error)
+	assert.NilError(t, uncached(env).LogCustomerInQueue(ctx, customer, ride))
+
+	err := run(env, "replay", "-customer", fmt.Sprint(customer.ID))
+
+	assert.NilError(t, err)
+	replayed := struct {
+		State customersEvents.CustomerState `json:"state"`
+	}{}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &replayed))
+	assert.Equal(t, true, replayed.State.Queueing)
+	assert.Equal(t, ride.ID, replayed.State.RideID)
+
+	out.Reset()
+	err = run(env, "snapshot")
+	assert.NilError(t, err)
+	snapshot := struct {
+		At    time.Time `json:"at"`
+		Rides []struct {
+			ID      uint `json:"id"`
+			InQueue uint `json:"in_queue_count"`
+		} `json:"rides"`
+	}{}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &snapshot))
+	assert.Assert(t, snapshot.At.Equal(at))
+	assert.Equal(t, 1, len(snapshot.Rides))
+	assert.Equal(t, ride.ID, snapshot.Rides[0].ID)
+	assert.Equal(t, uint(1), snapshot.Rides[0].InQueue)
+}
+
+func TestExportImportEvents(t *testing.T) {
+	ctx := context.Background()
+	env, out := testEnv(t, t.Name())
+	ride := &rides.Ride{Name: "Minions", Capacity: 2, RideTime: 60e9}
+	assert.NilError(t, rides.DAO{DB: env.DB}.Add(ride))
+	customer := &customers.Customer{}
+	assert.NilError(t, env.DB.Create(customer).Error)
+	// Appended directly as the export only needs the events, queueing through the services would
+	// read states cached by other tests for the same customer & ride IDs
+	eventDAO, now := events.DAO{DB: env.DB}, time.Now()
+	assert.NilError(t, eventDAO.Add(ctx, &ridesEvents.RideCustomerQueued{Ride: ride, Customer: customer, From: now, To: now.Add(time.Minute)}))
+	assert.NilError(t, eventDAO.Add(ctx, &customersEvents.CustomerQueued{Customer: customer, Ride: ride, From: now, To: now.Add(time.Minute)}))
+
+	err := run(env, "export-events")
+
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines), "expected a customer & a ride event")
+
+	imported, importedOut := testEnv(t, t.Name()+"Imported")
+	imported.In = strings.NewReader(out.String())
+	err = run(imported, "import-events")
+	assert.NilError(t, err)
+	assert.Equal(t, "Imported 2 events\n", importedOut.String())
+	exported, importedEvents := []events.Event{}, []events.Event{}
+	assert.NilError(t, env.DB.Table(events.TableName).Order("id").Find(&exported).Error)
+	assert.NilError(t, imported.DB.Table(events.TableName).Order("id").Find(&importedEvents).Error)
+	for i := range exported {
+		assert.Equal(t, exported[i].Name, importedEvents[i].Name)
+		assert.Equal(t, exported[i].SourceID, importedEvents[i].SourceID)
+		assert.Assert(t, exported[i].At.Equal(importedEvents[i].At))
+		assert.DeepEqual(t, exported[i].Data, importedEvents[i].Data)
+	}
+
+	t.Run("expected filtered columnar exports to be imported", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cli")
+		assert.NilError(t, err)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "events.columnar")
+
+		err = run(env, "export-events", "-format", "columnar", "-aggregate", "Ride", "-out", file)
+		assert.NilError(t, err)
+		columnar, _ := testEnv(t, t.Name())
+		err = run(columnar, "import-events", "-format", "columnar", "-in", file)
+		assert.NilError(t, err)
+		imported := []events.Event{}
+		assert.NilError(t, columnar.DB.Table(events.TableName).Find(&imported).Error)
+		assert.Equal(t, 1, len(imported))
+		assert.Equal(t, "RideCustomerQueued", imported[0].Name)
+
+		err = run(env, "export-events", "-from", "yesterday")
+		assert.Assert(t, errors.Is(err, cli.ErrUsage))
+	})
+
+	t.Run("expected unknown events not to be imported", func(t *testing.T) {
+		unknown := strings.Replace(lines[0], `"name":"`, `"name":"Teleported`, 1)
+		assert.Assert(t, unknown != lines[0])
+		imported.In = strings.NewReader(unknown)
+		err := run(imported, "import-events")
+		assert.Assert(t, errors.Is(err, events.ErrUnknownEvent))
+	})
+
+	t.Run("expected nothing to be imported when an event is invalid", func(t *testing.T) {
+		imported.In = strings.NewReader(lines[0] + "\n" + `{"name":"CustomerQueued"}`)
+		err := run(imported, "import-events")
+		assert.ErrorContains(t, err, "event 2")
+		var count int64
+		imported.DB.Table(events.TableName).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestUnknownCommand(t *testing.T) {
+	env, out := testEnv(t, t.Name())
+
+	err := run(env, "launch")
+
+	assert.Assert(t, errors.Is(err, cli.ErrUnknownCommand))
+	assert.Assert(t, strings.Contains(out.String(), "export-events"), "expected the usage to be printed")
+}
+
+func TestMigrateCommand(t *testing.T) {
+	env, out := testEnv(t, t.Name())
+
+	err := run(env, "migrate", "down", "-steps", "1")
+	assert.NilError(t, err)
+	assert.Equal(t, "Rolled back 2 index_events_aggregate_source_at\n", out.String())
+
+	out.Reset()
+	err = run(env, "migrate", "status")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), "pending"))
+}
+
+func TestSimulateCommand(t *testing.T) {
+	env, out := testEnv(t, t.Name())
+
+	err := run(env, "simulate", "-duration", "10m", "-arrivals-per-hour", "300", "-json")
+
+	assert.NilError(t, err)
+	report := struct {
+		Seed  int64 `json:"seed"`
+		Total struct {
+			Arrivals int `json:"arrivals"`
+		} `json:"total"`
+	}{}
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, int64(1), report.Seed)
+	assert.Assert(t, report.Total.Arrivals > 0)
+	var rides int64
+	env.DB.Table("rides").Count(&rides)
+	assert.Equal(t, int64(0), rides, "expected the simulation not to touch the env's DB")
+
+	err = run(env, "simulate", "-duration", "0s")
+	assert.ErrorContains(t, err, "Invalid simulation config")
+}
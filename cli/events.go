@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/events"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+	"gitlab.com/therako/universal-studios/logging"
+)
+
+// exportEvents writes the events matching the filters in the order they were added
+func exportEvents(ctx context.Context, env Env, args []string) (err error) {
+	flags := newFlags(env, "export-events")
+	out := flags.String("out", "", "file to write to instead of stdout")
+	format := flags.String("format", events.FormatJSONL, "jsonl or columnar")
+	aggregate := flags.String("aggregate", "", "only events of the aggregate root, eg. Ride or Customer")
+	name := flags.String("name", "", "only events with the name, eg. CustomerQueued")
+	from := flags.String("from", "", "only events at or after the RFC3339 time")
+	till := flags.String("till", "", "only events before the RFC3339 time")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+	filter := events.ExportFilter{AggregateRoot: *aggregate, Name: *name}
+	filter.From, err = parseTime("from", *from)
+	if err != nil {
+		return
+	}
+	filter.Till, err = parseTime("till", *till)
+	if err != nil {
+		return
+	}
+
+	w, err := output(env, *out)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	encoder, err := events.NewEncoder(*format, w)
+	if err != nil {
+		return
+	}
+
+	count, err := events.DAO{DB: env.DB}.Export(ctx, filter, encoder)
+	if err != nil {
+		return
+	}
+	err = encoder.Close()
+	if err != nil {
+		return
+	}
+	logging.FromContext(ctx).Info("Events exported", "count", count, "format", *format)
+	return
+}
+
+// importEvents validates the events of an export & appends them as new events, all or none of them
+func importEvents(ctx context.Context, env Env, args []string) (err error) {
+	flags := newFlags(env, "import-events")
+	in := flags.String("in", "", "file to read from instead of stdin")
+	format := flags.String("format", events.FormatJSONL, "jsonl or columnar")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+
+	r, err := input(env, *in)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	decoder, err := events.NewDecoder(*format, r)
+	if err != nil {
+		return
+	}
+
+	count, err := events.DAO{DB: env.DB}.Import(ctx, decoder, validateEvent)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(env.Out, "Imported %d events\n", count)
+	return
+}
+
+// validateEvent checks the event is a known event of it's aggregate
+func validateEvent(event *events.Event) error {
+	switch event.AggregateRoot {
+	case ridesEvents.AggregateRoot:
+		return ridesEvents.ValidateEvent(event)
+	case customersEvents.AggregateRoot:
+		return customersEvents.ValidateEvent(event)
+	default:
+		return fmt.Errorf("%w: unknown aggregate_root %q", events.ErrUnknownEvent, event.AggregateRoot)
+	}
+}
+
+// parseTime parses the RFC3339 time flag, nil when it's empty
+func parseTime(flag string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: -%s must be an RFC3339 time, eg. 2021-01-02T15:04:05Z", ErrUsage, flag)
+	}
+	return &t, nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"gitlab.com/therako/universal-studios/data/migrations"
+)
+
+const migrateUsage = `Usage: universal-studios migrate [command]
+
+Commands:
+  up [-to version]   apply pending migrations, up to & including the version when given (default)
+  down [-steps n]    roll back the last n applied migrations, 1 by default
+  status             list migrations & when they were applied
+`
+
+// migrate applies, rolls back or lists the migrations, writing what was done to the env's output
+func migrate(ctx context.Context, env Env, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	migrator := migrations.New(env.DB)
+	flags := newFlags(env, "migrate "+command)
+	flags.Usage = func() { fmt.Fprint(env.Out, migrateUsage) }
+
+	switch command {
+	case "up":
+		to := flags.Uint("to", 0, "version to migrate up to")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx, *to)
+		for _, migration := range applied {
+			fmt.Fprintf(env.Out, "Applied %d %s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "down":
+		steps := flags.Int("steps", 1, "no of migrations to roll back")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Fprintf(env.Out, "Rolled back %d %s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(env.Out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		flags.Usage()
+		return fmt.Errorf("%w: unknown migrate command %q", ErrUsage, command)
+	}
+}
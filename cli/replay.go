@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/therako/universal-studios/clock"
+	customersData "gitlab.com/therako/universal-studios/data/customers"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+)
+
+// replay rebuilds a ride's or customer's state from it's events & prints it
+func replay(ctx context.Context, env Env, args []string) error {
+	flags := newFlags(env, "replay")
+	rideID := flags.Uint("ride", 0, "id of the ride to replay")
+	customerID := flags.Uint("customer", 0, "id of the customer to replay")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*rideID == 0) == (*customerID == 0) {
+		flags.Usage()
+		return fmt.Errorf("%w: either -ride or -customer is required", ErrUsage)
+	}
+
+	if *rideID != 0 {
+		ride, err := ridesData.DAO{DB: env.DB}.Get(*rideID)
+		if err != nil {
+			return err
+		}
+		state, err := services(env).Rides.Replay(ctx, ride)
+		if err != nil {
+			return err
+		}
+		state.Apply(ride, clock.Or(env.Config.Clock).Now())
+		return printJSON(env.Out, map[string]interface{}{"ride": ride, "state": state})
+	}
+
+	customer, err := customersData.DAO{DB: env.DB}.Get(*customerID)
+	if err != nil {
+		return err
+	}
+	state, err := services(env).Customers.Replay(ctx, customer)
+	if err != nil {
+		return err
+	}
+	return printJSON(env.Out, map[string]interface{}{"customer": customer, "state": state})
+}
+
+// rideSnapshot is a ride with it's waiting times applied along with it's state
+type rideSnapshot struct {
+	*ridesData.Ride
+	State *ridesEvents.RideState `json:"state"`
+}
+
+// snapshot writes the current state of every ride in service
+func snapshot(ctx context.Context, env Env, args []string) error {
+	flags := newFlags(env, "snapshot")
+	out := flags.String("out", "", "file to write to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	at := clock.Or(env.Config.Clock).Now()
+	rides, err := ridesData.DAO{DB: env.DB}.List(ridesData.Filter{})
+	if err != nil {
+		return err
+	}
+	service := services(env).Rides
+	snapshots := make([]rideSnapshot, 0, len(rides))
+	for _, ride := range rides {
+		state, err := service.Replay(ctx, ride)
+		if err != nil {
+			return err
+		}
+		state.Apply(ride, at)
+		snapshots = append(snapshots, rideSnapshot{Ride: ride, State: state})
+	}
+
+	w, err := output(env, *out)
+	if err != nil {
+		return err
+	}
+	err = printJSON(w, map[string]interface{}{"at": at, "rides": snapshots})
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
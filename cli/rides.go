@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/rides"
+)
+
+// addRide adds a new ride, printing it
+func addRide(ctx context.Context, env Env, args []string) error {
+	flags := newFlags(env, "add-ride")
+	name := flags.String("name", "", "name of the ride (required)")
+	desc := flags.String("desc", "", "description of the ride")
+	rideTimeSecs := flags.Uint("ride-time-secs", 0, "how long a ride takes (required)")
+	capacity := flags.Uint("capacity", 0, "no of customers on each ride (required)")
+	zone := flags.String("zone", "", "zone of the studio the ride is in")
+	minHeightCm := flags.Uint("min-height-cm", 0, "min height of guests allowed on the ride")
+	minAge := flags.Uint("min-age", 0, "min age of guests allowed on the ride")
+	thrillLevel := flags.Uint("thrill-level", 0, "how thrilling the ride is")
+	accessibility := flags.String("accessibility", "", "comma separated accessibility features")
+	tags := flags.String("tags", "", "comma separated tags")
+	queueTypes := flags.String("queue-types", "", "comma separated queue types other than standby, eg. single_rider,virtual")
+	priorityMergeRatio := flags.Uint("priority-merge-ratio", 0, "no of priority customers boarding in each batch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *rideTimeSecs == 0 || *capacity == 0 {
+		flags.Usage()
+		return fmt.Errorf("%w: -name, -capacity & -ride-time-secs are required", ErrUsage)
+	}
+
+	ride := &rides.Ride{
+		Name:               *name,
+		Desc:               *desc,
+		Capacity:           *capacity,
+		RideTime:           time.Duration(*rideTimeSecs) * time.Second,
+		Zone:               *zone,
+		MinHeightCm:        *minHeightCm,
+		MinAge:             *minAge,
+		ThrillLevel:        *thrillLevel,
+		Accessibility:      list(*accessibility),
+		Tags:               list(*tags),
+		QueueTypes:         list(*queueTypes),
+		PriorityMergeRatio: *priorityMergeRatio,
+	}
+	err := rides.DAO{DB: env.DB.WithContext(ctx)}.Add(ride)
+	if err != nil {
+		return err
+	}
+	return printJSON(env.Out, ride)
+}
+
+// closeRide marks a ride as down, or retires it un-queueing the customers still in it's queues
+func closeRide(ctx context.Context, env Env, args []string) error {
+	flags := newFlags(env, "close-ride")
+	id := flags.Uint("id", 0, "id of the ride (required)")
+	retire := flags.Bool("retire", false, "retire the ride instead of marking it as down")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		flags.Usage()
+		return fmt.Errorf("%w: -id is required", ErrUsage)
+	}
+
+	ride, err := rides.DAO{DB: env.DB}.Get(*id)
+	if err != nil {
+		return err
+	}
+	if *retire {
+		unQueued, err := services(env).Customers.RetireRide(ctx, ride)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Out, "Retired ride %d, un-queued %d customers\n", ride.ID, len(unQueued))
+		return nil
+	}
+
+	err = services(env).Rides.LogRideStatus(ctx, ride, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Out, "Marked ride %d as down\n", ride.ID)
+	return nil
+}
+
+// list splits a comma separated flag, nil when it's empty
+func list(value string) []string {
+	if value == "" {
+		return nil
+	}
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
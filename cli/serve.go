@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/therako/universal-studios/api"
+	"gitlab.com/therako/universal-studios/data/migrations"
+	"gitlab.com/therako/universal-studios/logging"
+)
+
+// serve applies the pending migrations unless disabled & serves the APIs till SIGINT or SIGTERM
+func serve(ctx context.Context, env Env, args []string) error {
+	flags := newFlags(env, "serve")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	fmt.Fprintln(env.Out, "Welcome to Universal Studios")
+
+	if !env.Config.MigrateOnStartDisabled {
+		_, err := migrations.New(env.DB).Up(ctx, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	server := api.NewServer(ctx, env.Config, env.DB)
+	return server.Run(signalContext(ctx))
+}
+
+// signalContext returns a copy of ctx which is done on SIGINT or SIGTERM
+func signalContext(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logging.FromContext(ctx).Info("Received signal", "signal", sig.String())
+		signal.Stop(signals)
+		cancel()
+	}()
+	return ctx
+}
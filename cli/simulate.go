@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"gitlab.com/therako/universal-studios/data/drivers"
+	"gitlab.com/therako/universal-studios/data/migrations"
+	"gitlab.com/therako/universal-studios/logging"
+	"gitlab.com/therako/universal-studios/simulation"
+)
+
+// simulationDSN is the in-memory SQLite DB simulations run against, never the configured DB
+const simulationDSN = "file:simulation?mode=memory"
+
+// simulate runs a simulated day at the park & prints how accurate the quoted waits were
+func simulate(ctx context.Context, env Env, args []string) (err error) {
+	config := simulation.DefaultConfig()
+	flags := newFlags(env, "simulate")
+	flags.Int64Var(&config.Seed, "seed", config.Seed, "seed of the random arrivals, choices & patience")
+	flags.DurationVar(&config.Duration, "duration", config.Duration, "how long guests arrive for")
+	flags.Float64Var(&config.ArrivalsPerHour, "arrivals-per-hour", config.ArrivalsPerHour, "mean rate guests arrive at")
+	flags.DurationVar(&config.MeanPatience, "patience", config.MeanPatience, "mean time guests are willing to wait, 0 to never abandon")
+	flags.Float64Var(&config.WaitSensitivity, "wait-sensitivity", config.WaitSensitivity, "how much long quotes put guests off a ride")
+	flags.DurationVar(&config.Tolerance, "tolerance", config.Tolerance, "how far off a quote can be & still count as accurate")
+	asJSON := flags.Bool("json", false, "print the report as JSON instead of a table")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+
+	// Every simulated guest's queueing is logged at info
+	ctx = logging.WithContext(ctx, logging.New(os.Stderr, slog.LevelWarn))
+	db, err := drivers.Open(drivers.SQLite, simulationDSN, drivers.Pool{MaxOpenConns: 1}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return
+	}
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		defer sqlDB.Close()
+	}
+	_, err = migrations.New(db).Up(ctx, 0)
+	if err != nil {
+		return
+	}
+
+	report, err := simulation.Run(ctx, db, config)
+	if err != nil {
+		return
+	}
+	if *asJSON {
+		return printJSON(env.Out, report)
+	}
+	return report.Write(env.Out)
+}
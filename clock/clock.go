@@ -0,0 +1,25 @@
+// Package clock tells the studio's time. Structs needing the time take a Clock so the whole flow,
+// from entering the studio to replaying states, can run on a fake clock in tests & simulations.
+package clock
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// Clock tells the current time, clockwork's real & fake clocks are Clocks
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the system clock
+var Real Clock = clockwork.NewRealClock()
+
+// Or returns c, the real clock when c is nil so zero value structs tell the real time
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
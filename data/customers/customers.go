@@ -1,9 +1,14 @@
 package customers
 
 import (
+	"errors"
 	"time"
 
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/events"
 	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gitlab.com/therako/universal-studios/domain"
 	"gorm.io/gorm"
 )
 
@@ -12,29 +17,169 @@ const (
 	TableName = "customers"
 )
 
+// Errors
+var (
+	ErrTicketInUse     = domain.NewError(domain.Conflict, "ticket_in_use", "Ticket is in use by a customer inside the studio")
+	ErrUnknownStatus   = domain.NewError(domain.Invalid, "unknown_customer_status", "Unknown customer status, expected in_park, exited or all")
+	ErrInvalidPageSize = domain.NewError(domain.Invalid, "invalid_page_size", "Page size must be between 1 & 1000")
+)
+
+// Page sizes when listing customers
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// eventsAggregateRoot of the customer events, same as events/customers.AggregateRoot
+// which can't be imported here
+const eventsAggregateRoot = "Customer"
+
 // Customer DB model for the studios
 type Customer struct {
 	models.Model
 	// For simplicity let's ignore all customer personal info and use just ID's
-	ExitAt *time.Time `gorm:"column:exit_at" json:"exit_at"`
+	ExitAt   *time.Time `gorm:"column:exit_at" json:"exit_at"`
+	PartyID  *uint      `gorm:"column:party_id" json:"party_id"`
+	TicketID *uint      `gorm:"column:ticket_id" json:"ticket_id"`
 }
 
 // DAO is data access object for customer
 type DAO struct {
 	DB *gorm.DB
+	// Clock tells when customers enter & exit, the real clock when nil
+	Clock clock.Clock
 }
 
-// List returns a list of all customers inside the studio
-func (r DAO) List() (customers []*Customer, err error) {
-	err = r.DB.Table(TableName).Where("exit_at IS NULL").Find(&customers).Error
-	return
+func (r DAO) now() time.Time {
+	return clock.Or(r.Clock).Now()
+}
+
+// Status of the customer in the studio
+type Status string
+
+// Customer statuses
+const (
+	InPark    Status = "in_park"
+	Exited    Status = "exited"
+	AnyStatus Status = "all"
+)
+
+// Filter narrows down the list of customers, zero values are ignored
+type Filter struct {
+	// Status defaults to customers inside the studio
+	Status Status
+	// Queueing when set returns only customers who are or aren't in a queue now
+	Queueing    *bool
+	CreatedFrom *time.Time
+	CreatedTill *time.Time
+	// After is the cursor, only customers with a larger id are returned
+	After uint
+	// PageSize defaults to DefaultPageSize
+	PageSize int
+}
+
+// List returns a page of customers matching the filter ordered by id, along with the cursor
+// for the next page which is 0 on the last page
+func (r DAO) List(filter Filter) (customers []*Customer, next uint, err error) {
+	if filter.PageSize == 0 {
+		filter.PageSize = DefaultPageSize
+	}
+	if filter.PageSize < 0 || filter.PageSize > MaxPageSize {
+		return nil, 0, ErrInvalidPageSize
+	}
+
+	query := r.DB.Table(TableName)
+	switch filter.Status {
+	case "", InPark:
+		query = query.Where("exit_at IS NULL")
+	case Exited:
+		query = query.Where("exit_at IS NOT NULL")
+	case AnyStatus:
+	default:
+		return nil, 0, ErrUnknownStatus
+	}
+	if filter.Queueing != nil {
+		// A customer is queueing when their latest event is a CustomerQueued which hasn't ended
+		queueing := r.DB.Table(events.TableName+" AS q").Select("1").Where(
+			"q.source_id = customers.id AND q.aggregate_root = ? AND q.name = ? AND q.ends_at > ?",
+			eventsAggregateRoot, "CustomerQueued", r.now(),
+		).Where(
+			"NOT EXISTS (?)",
+			r.DB.Table(events.TableName+" AS l").Select("1").Where(
+				"l.source_id = q.source_id AND l.aggregate_root = q.aggregate_root AND l.at > q.at",
+			),
+		)
+		if *filter.Queueing {
+			query = query.Where("EXISTS (?)", queueing)
+		} else {
+			query = query.Where("NOT EXISTS (?)", queueing)
+		}
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTill != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTill)
+	}
+	if filter.After != 0 {
+		query = query.Where("id > ?", filter.After)
+	}
+
+	// One extra row tells if there's a next page
+	err = query.Order("id").Limit(filter.PageSize + 1).Find(&customers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(customers) > filter.PageSize {
+		customers = customers[:filter.PageSize]
+		next = customers[len(customers)-1].ID
+	}
+	return customers, next, nil
 }
 
-// Enter marks a new customer entrying the studio
-func (r DAO) Enter() (*Customer, error) {
-	newCustomer := &Customer{ExitAt: nil}
-	err := r.DB.Create(newCustomer).Error
-	return newCustomer, err
+// Enter validates & scans the ticket with the code marking the customer entrying the studio.
+// A customer who exited earlier is re-activated if the ticket allows re-entry,
+// else a new customer is created for the ticket. The ticket is read, validated & scanned
+// in one transaction, concurrent entries on it fail with tickets.ErrTicketScanConflict.
+func (r DAO) Enter(code string) (customer *Customer, reEntered bool, err error) {
+	now := r.now()
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		ticketDAO := tickets.DAO{DB: tx}
+		ticket, err := ticketDAO.GetByCode(code)
+		if err != nil {
+			return err
+		}
+		err = ticket.ValidateEntry(now)
+		if err != nil {
+			return err
+		}
+
+		customer = &Customer{}
+		err = tx.Where("ticket_id = ?", ticket.ID).Order("id desc").First(customer).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			customer = &Customer{TicketID: models.UintP(ticket.ID)}
+			err = tx.Create(customer).Error
+		case err != nil:
+			return err
+		case customer.ExitAt == nil:
+			return ErrTicketInUse
+		default:
+			reEntered = true
+			customer.ExitAt = nil
+			err = tx.Save(customer).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return ticketDAO.Scan(ticket, now)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return customer, reEntered, nil
 }
 
 // Exit marks a the customer leving the studio
@@ -45,7 +190,7 @@ func (r DAO) Exit(id uint) (customer *Customer, err error) {
 		return
 	}
 
-	customer.ExitAt = models.TimeP(time.Now())
+	customer.ExitAt = models.TimeP(r.now())
 	err = r.DB.Save(&customer).Error
 	if err != nil {
 		return nil, err
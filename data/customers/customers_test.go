@@ -0,0 +1,126 @@
+package customers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+var tables = []interface{}{&tickets.Ticket{}, &customers.Customer{}, &events.Event{}}
+
+func ids(list []*customers.Customer) []uint {
+	result := []uint{}
+	for _, customer := range list {
+		result = append(result, customer.ID)
+	}
+	return result
+}
+
+func TestEnterAndExit(t *testing.T) {
+	driverstest.ForEach(t, tables, func(t *testing.T, db *gorm.DB) {
+		clock := clockwork.NewFakeClockAt(time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC))
+		dao := customers.DAO{DB: db, Clock: clock}
+		ticketDAO := tickets.DAO{DB: db}
+		assert.NilError(t, ticketDAO.Add(&tickets.Ticket{Code: "day", ValidFrom: clock.Now(), ValidTill: clock.Now().Add(12 * time.Hour)}))
+
+		customer, reEntered, err := dao.Enter("day")
+		assert.NilError(t, err)
+		assert.Assert(t, !reEntered)
+
+		clock.Advance(time.Hour)
+		exited, err := dao.Exit(customer.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, exited.ExitAt.Equal(clock.Now()))
+
+		_, _, err = dao.Enter("day")
+		assert.Equal(t, tickets.ErrTicketReEntryNotAllowed, err)
+		ticket, err := ticketDAO.GetByCode("day")
+		assert.NilError(t, err)
+		assert.Equal(t, uint(1), ticket.ScanCount, "expected a failed entry not to scan the ticket")
+	})
+}
+
+func TestReEnter(t *testing.T) {
+	driverstest.ForEach(t, tables, func(t *testing.T, db *gorm.DB) {
+		clock := clockwork.NewFakeClockAt(time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC))
+		dao := customers.DAO{DB: db, Clock: clock}
+		assert.NilError(t, tickets.DAO{DB: db}.Add(&tickets.Ticket{
+			Code: "annual", ValidFrom: clock.Now(), ValidTill: clock.Now().AddDate(1, 0, 0), ReEntry: tickets.SameDayReEntry,
+		}))
+		customer, _, err := dao.Enter("annual")
+		assert.NilError(t, err)
+		_, _, err = dao.Enter("annual")
+		assert.Equal(t, customers.ErrTicketInUse, err)
+		_, err = dao.Exit(customer.ID)
+		assert.NilError(t, err)
+
+		reEntry, reEntered, err := dao.Enter("annual")
+
+		assert.NilError(t, err)
+		assert.Assert(t, reEntered)
+		assert.Equal(t, customer.ID, reEntry.ID)
+		assert.Assert(t, reEntry.ExitAt == nil)
+	})
+}
+
+func TestList(t *testing.T) {
+	driverstest.ForEach(t, tables, func(t *testing.T, db *gorm.DB) {
+		now := time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		dao := customers.DAO{DB: db, Clock: clockwork.NewFakeClockAt(now)}
+		for i := 0; i < 5; i++ {
+			assert.NilError(t, db.Create(&customers.Customer{}).Error)
+		}
+		_, err := dao.Exit(5)
+		assert.NilError(t, err)
+		// 1 is queueing, 2 left the queue & 3's ride ended
+		for _, event := range []*events.Event{
+			{SourceID: 1, AggregateRoot: "Customer", Name: "CustomerQueued", At: now.Add(-time.Minute), EndsAt: models.TimeP(now.Add(time.Hour))},
+			{SourceID: 2, AggregateRoot: "Customer", Name: "CustomerQueued", At: now.Add(-2 * time.Minute), EndsAt: models.TimeP(now.Add(time.Hour))},
+			{SourceID: 2, AggregateRoot: "Customer", Name: "CustomerUnQueued", At: now.Add(-time.Minute)},
+			{SourceID: 3, AggregateRoot: "Customer", Name: "CustomerQueued", At: now.Add(-time.Hour), EndsAt: models.TimeP(now.Add(-time.Minute))},
+		} {
+			assert.NilError(t, db.Create(event).Error)
+		}
+		queueing, notQueueing := true, false
+
+		for name, test := range map[string]struct {
+			filter   customers.Filter
+			expected []uint
+		}{
+			"in the park":      {customers.Filter{}, []uint{1, 2, 3, 4}},
+			"exited":           {customers.Filter{Status: customers.Exited}, []uint{5}},
+			"any status":       {customers.Filter{Status: customers.AnyStatus}, []uint{1, 2, 3, 4, 5}},
+			"queueing":         {customers.Filter{Queueing: &queueing}, []uint{1}},
+			"not queueing":     {customers.Filter{Queueing: &notQueueing}, []uint{2, 3, 4}},
+			"after the cursor": {customers.Filter{After: 2}, []uint{3, 4}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				list, _, err := dao.List(test.filter)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, test.expected, ids(list))
+			})
+		}
+
+		page, next, err := dao.List(customers.Filter{PageSize: 3})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []uint{1, 2, 3}, ids(page))
+		assert.Equal(t, uint(3), next)
+		page, next, err = dao.List(customers.Filter{PageSize: 3, After: next})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []uint{4}, ids(page))
+		assert.Equal(t, uint(0), next)
+
+		_, _, err = dao.List(customers.Filter{Status: "asleep"})
+		assert.Equal(t, customers.ErrUnknownStatus, err)
+		_, _, err = dao.List(customers.Filter{PageSize: customers.MaxPageSize + 1})
+		assert.Equal(t, customers.ErrInvalidPageSize, err)
+	})
+}
@@ -0,0 +1,109 @@
+// Package drivers opens the studio's DB with the driver chosen in the config, so single node
+// deployments & local development can use a SQLite file instead of a Postgres server.
+package drivers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Drivers supported
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+)
+
+// Errors
+var (
+	ErrUnknownDriver = errors.New("Unknown DB driver, expected postgres, mysql or sqlite")
+)
+
+// mysqlStringSize is the size of string columns on MySQL, which can't index the default longtext
+const mysqlStringSize = 191
+
+// Pool settings of the DB connections, zero values leave the database/sql defaults
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Valid is true for the drivers Open supports
+func Valid(driver string) bool {
+	switch driver {
+	case Postgres, MySQL, SQLite:
+		return true
+	}
+	return false
+}
+
+// Dialector returns the gorm dialector of the driver connecting to the dsn, for SQLite the dsn is
+// the file path eg. `studios.db` or `file:studios.db?_busy_timeout=5000`
+func Dialector(driver string, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case Postgres:
+		return postgres.Open(dsn), nil
+	case MySQL:
+		return mysql.New(mysql.Config{DSN: dsn, DefaultStringSize: mysqlStringSize}), nil
+	case SQLite:
+		return sqlite.Open(sqliteDSN(dsn)), nil
+	default:
+		return nil, ErrUnknownDriver
+	}
+}
+
+// sqlitePragmas are set on every SQLite connection unless the dsn sets them. SQLite defaults to
+// `foreign_keys = off`, & writers wait on each other instead of failing with `database is locked`
+var sqlitePragmas = []struct{ key, value string }{
+	{"_foreign_keys", "1"},
+	{"_busy_timeout", "5000"},
+	{"_journal_mode", "WAL"},
+}
+
+func sqliteDSN(dsn string) string {
+	for _, pragma := range sqlitePragmas {
+		if strings.Contains(dsn, pragma.key+"=") {
+			continue
+		}
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + pragma.key + "=" + pragma.value
+	}
+	return dsn
+}
+
+// Open connects to the DB with the driver & sets up the connection pool
+func Open(driver string, dsn string, pool Pool, config *gorm.Config) (*gorm.DB, error) {
+	dialector, err := Dialector(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	gormDB, err := gorm.Open(dialector, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	if pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	return gormDB, nil
+}
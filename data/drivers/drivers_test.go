@@ -0,0 +1,40 @@
+package drivers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/drivers"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("expected to fail for unknown drivers", func(t *testing.T) {
+		_, err := drivers.Open("oracle", "", drivers.Pool{}, &gorm.Config{})
+		assert.Equal(t, drivers.ErrUnknownDriver, err)
+	})
+
+	t.Run("expected to create a SQLite file with foreign keys on & the pool set", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "drivers")
+		assert.NilError(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "studios.db")
+
+		db, err := drivers.Open(drivers.SQLite, path, drivers.Pool{MaxOpenConns: 3, ConnMaxLifetime: time.Minute}, &gorm.Config{})
+
+		assert.NilError(t, err)
+		sqlDB, err := db.DB()
+		assert.NilError(t, err)
+		defer sqlDB.Close()
+		assert.Equal(t, 3, sqlDB.Stats().MaxOpenConnections)
+		var foreignKeys int
+		assert.NilError(t, db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error)
+		assert.Equal(t, 1, foreignKeys)
+		_, err = os.Stat(path)
+		assert.NilError(t, err)
+	})
+}
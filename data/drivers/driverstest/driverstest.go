@@ -0,0 +1,56 @@
+// Package driverstest runs the DAO tests against every DB driver, so queries like the LIKE filters &
+// optimistic updates are checked on each dialect & not only on SQLite.
+package driverstest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/therako/universal-studios/data/drivers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gotest.tools/v3/assert"
+)
+
+// ForEach runs the test against empty tables of the models on every driver that can run locally,
+// an in memory & a file SQLite DB always & Postgres or MySQL when TEST_POSTGRES_DSN or TEST_MYSQL_DSN
+// point at a DB. Models are migrated in the order given, so tables referred to by foreign keys go first.
+func ForEach(t *testing.T, models []interface{}, test func(t *testing.T, db *gorm.DB)) {
+	dir, err := ioutil.TempDir("", "driverstest")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	dsns := map[string]string{
+		"sqlite_memory": fmt.Sprintf("file:%s?mode=memory", strings.ReplaceAll(t.Name(), "/", "_")),
+		"sqlite_file":   filepath.Join(dir, "studios.db"),
+		"postgres":      os.Getenv("TEST_POSTGRES_DSN"),
+		"mysql":         os.Getenv("TEST_MYSQL_DSN"),
+	}
+	for _, name := range []string{"sqlite_memory", "sqlite_file", "postgres", "mysql"} {
+		t.Run(name, func(t *testing.T) {
+			driver := strings.TrimSuffix(strings.TrimSuffix(name, "_memory"), "_file")
+			if dsns[name] == "" {
+				t.Skipf("set TEST_%s_DSN to run against %s", strings.ToUpper(driver), driver)
+			}
+			pool := drivers.Pool{}
+			if name == "sqlite_memory" {
+				pool.MaxOpenConns = 1 // Every connection opens a new in-memory DB
+			}
+			db, err := drivers.Open(driver, dsns[name], pool, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			assert.NilError(t, err)
+			sqlDB, err := db.DB()
+			assert.NilError(t, err)
+			defer sqlDB.Close()
+
+			// Servers are shared across tests, start each with empty tables
+			assert.NilError(t, db.Migrator().DropTable(models...))
+			assert.NilError(t, db.AutoMigrate(models...))
+
+			test(t, db)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// The columnar format is a gzip stream of:
+//
+//	magic "USEV" | version byte | row groups... | 0
+//
+// Each row group is the uvarint no of events in it followed by a chunk per column, each chunk is
+// it's uvarint length & the column's values so readers can skip the columns they don't need. Ids &
+// times are varint deltas from the previous event, ends_at a presence byte & the varint duration
+// after at, aggregate_root & name indexes into a dictionary of the row group's values & data is
+// length prefixed. Events are append only, so updated_at & deleted_at aren't kept.
+
+// Errors
+var (
+	ErrInvalidColumnar = errors.New("Invalid columnar export")
+)
+
+var columnarMagic = []byte("USEV")
+
+const (
+	columnarVersion = 1
+	// columnarRowGroupSize is the max no of events in a row group
+	columnarRowGroupSize = 4096
+	// columnarMaxChunkSize bounds what's allocated for a column chunk of a corrupt export
+	columnarMaxChunkSize = 1 << 30
+)
+
+// Columns of the columnar format in the order they're written
+const (
+	columnID = iota
+	columnCreatedAt
+	columnSourceID
+	columnAt
+	columnEndsAt
+	columnAggregateRoot
+	columnName
+	columnData
+	columnCount
+)
+
+type columnarEncoder struct {
+	gzip     *gzip.Writer
+	buffered *bufio.Writer
+	rows     []*Event
+	started  bool
+}
+
+func newColumnarEncoder(w io.Writer) *columnarEncoder {
+	gz := gzip.NewWriter(w)
+	return &columnarEncoder{gzip: gz, buffered: bufio.NewWriter(gz)}
+}
+
+func (e *columnarEncoder) Encode(event *Event) error {
+	e.rows = append(e.rows, event)
+	if len(e.rows) < columnarRowGroupSize {
+		return nil
+	}
+	return e.writeRowGroup()
+}
+
+func (e *columnarEncoder) Close() error {
+	err := e.writeRowGroup()
+	if err != nil {
+		return err
+	}
+	err = e.writeHeader()
+	if err != nil {
+		return err
+	}
+	err = writeUvarint(e.buffered, 0)
+	if err != nil {
+		return err
+	}
+	err = e.buffered.Flush()
+	if err != nil {
+		return err
+	}
+	return e.gzip.Close()
+}
+
+func (e *columnarEncoder) writeHeader() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	_, err := e.buffered.Write(append(columnarMagic, columnarVersion))
+	return err
+}
+
+func (e *columnarEncoder) writeRowGroup() error {
+	err := e.writeHeader()
+	if err != nil || len(e.rows) == 0 {
+		return err
+	}
+
+	columns := make([]columnWriter, columnCount)
+	aggregateRoots, names := dictionary{}, dictionary{}
+	var lastID uint
+	var lastCreatedAt, lastAt int64
+	for _, event := range e.rows {
+		columns[columnID].varint(int64(event.ID) - int64(lastID))
+		lastID = event.ID
+		columns[columnCreatedAt].varint(event.CreatedAt.UnixNano() - lastCreatedAt)
+		lastCreatedAt = event.CreatedAt.UnixNano()
+		columns[columnSourceID].uvarint(uint64(event.SourceID))
+		columns[columnAt].varint(event.At.UnixNano() - lastAt)
+		lastAt = event.At.UnixNano()
+		if event.EndsAt == nil {
+			columns[columnEndsAt].WriteByte(0)
+		} else {
+			columns[columnEndsAt].WriteByte(1)
+			columns[columnEndsAt].varint(int64(event.EndsAt.Sub(event.At)))
+		}
+		columns[columnAggregateRoot].uvarint(aggregateRoots.index(event.AggregateRoot))
+		columns[columnName].uvarint(names.index(event.Name))
+		columns[columnData].bytes(event.Data)
+	}
+	// The dictionaries lead their columns
+	columns[columnAggregateRoot] = aggregateRoots.prepend(columns[columnAggregateRoot])
+	columns[columnName] = names.prepend(columns[columnName])
+
+	err = writeUvarint(e.buffered, uint64(len(e.rows)))
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		err = writeUvarint(e.buffered, uint64(column.Len()))
+		if err != nil {
+			return err
+		}
+		_, err = e.buffered.Write(column.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	e.rows = e.rows[:0]
+	return nil
+}
+
+type columnarDecoder struct {
+	r    *bufio.Reader
+	rows []*Event
+	done bool
+}
+
+func newColumnarDecoder(r io.Reader) (*columnarDecoder, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidColumnar
+	}
+	d := &columnarDecoder{r: bufio.NewReader(gz)}
+	header := make([]byte, len(columnarMagic)+1)
+	_, err = io.ReadFull(d.r, header)
+	if err != nil || !bytes.Equal(header[:len(columnarMagic)], columnarMagic) || header[len(columnarMagic)] != columnarVersion {
+		return nil, ErrInvalidColumnar
+	}
+	return d, nil
+}
+
+func (d *columnarDecoder) Decode() (*Event, error) {
+	for len(d.rows) == 0 {
+		if d.done {
+			return nil, io.EOF
+		}
+		err := d.readRowGroup()
+		if err != nil {
+			return nil, err
+		}
+	}
+	event := d.rows[0]
+	d.rows = d.rows[1:]
+	return event, nil
+}
+
+func (d *columnarDecoder) readRowGroup() error {
+	count, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return ErrInvalidColumnar
+	}
+	if count == 0 {
+		d.done = true
+		return nil
+	}
+	if count > columnarRowGroupSize {
+		return ErrInvalidColumnar
+	}
+
+	columns := make([]*columnReader, columnCount)
+	for i := range columns {
+		size, err := binary.ReadUvarint(d.r)
+		if err != nil || size > columnarMaxChunkSize {
+			return ErrInvalidColumnar
+		}
+		chunk := make([]byte, size)
+		_, err = io.ReadFull(d.r, chunk)
+		if err != nil {
+			return ErrInvalidColumnar
+		}
+		columns[i] = &columnReader{Reader: bytes.NewReader(chunk)}
+	}
+	aggregateRoots := columns[columnAggregateRoot].dictionary()
+	names := columns[columnName].dictionary()
+
+	rows := make([]*Event, count)
+	var lastID, lastCreatedAt, lastAt int64
+	for i := range rows {
+		event := &Event{}
+		lastID += columns[columnID].varint()
+		event.ID = uint(lastID)
+		lastCreatedAt += columns[columnCreatedAt].varint()
+		event.CreatedAt = time.Unix(0, lastCreatedAt).UTC()
+		event.UpdatedAt = event.CreatedAt
+		event.SourceID = uint(columns[columnSourceID].uvarint())
+		lastAt += columns[columnAt].varint()
+		event.At = time.Unix(0, lastAt).UTC()
+		if columns[columnEndsAt].byte() == 1 {
+			endsAt := event.At.Add(time.Duration(columns[columnEndsAt].varint()))
+			event.EndsAt = &endsAt
+		}
+		event.AggregateRoot = columns[columnAggregateRoot].lookup(aggregateRoots)
+		event.Name = columns[columnName].lookup(names)
+		event.Data = columns[columnData].bytes()
+		rows[i] = event
+	}
+	for _, column := range columns {
+		if column.err != nil {
+			return ErrInvalidColumnar
+		}
+	}
+	d.rows = rows
+	return nil
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	_, err := w.Write(buf[:binary.PutUvarint(buf, v)])
+	return err
+}
+
+// columnWriter buffers the values of a column chunk
+type columnWriter struct {
+	bytes.Buffer
+}
+
+func (c *columnWriter) uvarint(v uint64) {
+	writeUvarint(&c.Buffer, v)
+}
+
+func (c *columnWriter) varint(v int64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	c.Write(buf[:binary.PutVarint(buf, v)])
+}
+
+func (c *columnWriter) bytes(v []byte) {
+	c.uvarint(uint64(len(v)))
+	c.Write(v)
+}
+
+// columnReader reads the values of a column chunk, keeping the first error so the values can be
+// read without checking each one
+type columnReader struct {
+	*bytes.Reader
+	err error
+}
+
+func (c *columnReader) uvarint() uint64 {
+	v, err := binary.ReadUvarint(c.Reader)
+	c.fail(err)
+	return v
+}
+
+func (c *columnReader) varint() int64 {
+	v, err := binary.ReadVarint(c.Reader)
+	c.fail(err)
+	return v
+}
+
+func (c *columnReader) byte() byte {
+	v, err := c.ReadByte()
+	c.fail(err)
+	return v
+}
+
+func (c *columnReader) bytes() []byte {
+	size := c.uvarint()
+	if size > uint64(c.Len()) {
+		c.fail(ErrInvalidColumnar)
+		return nil
+	}
+	v := make([]byte, size)
+	_, err := io.ReadFull(c.Reader, v)
+	c.fail(err)
+	return v
+}
+
+func (c *columnReader) dictionary() []string {
+	size := c.uvarint()
+	if size > uint64(c.Len()) {
+		c.fail(ErrInvalidColumnar)
+		return nil
+	}
+	values := make([]string, size)
+	for i := range values {
+		values[i] = string(c.bytes())
+	}
+	return values
+}
+
+func (c *columnReader) lookup(dictionary []string) string {
+	i := c.uvarint()
+	if i >= uint64(len(dictionary)) {
+		c.fail(ErrInvalidColumnar)
+		return ""
+	}
+	return dictionary[i]
+}
+
+func (c *columnReader) fail(err error) {
+	if c.err == nil && err != nil {
+		c.err = err
+	}
+}
+
+// dictionary assigns each distinct value of a column an index in the order they're seen
+type dictionary struct {
+	values  []string
+	indexes map[string]uint64
+}
+
+func (d *dictionary) index(value string) uint64 {
+	if d.indexes == nil {
+		d.indexes = map[string]uint64{}
+	}
+	i, ok := d.indexes[value]
+	if !ok {
+		i = uint64(len(d.values))
+		d.indexes[value] = i
+		d.values = append(d.values, value)
+	}
+	return i
+}
+
+// prepend returns the column led by the dictionary's values
+func (d *dictionary) prepend(column columnWriter) columnWriter {
+	withValues := columnWriter{}
+	withValues.uvarint(uint64(len(d.values)))
+	for _, value := range d.values {
+		withValues.bytes([]byte(value))
+	}
+	withValues.Write(column.Bytes())
+	return withValues
+}
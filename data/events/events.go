@@ -1,9 +1,13 @@
 package events
 
 import (
+	"context"
 	"time"
 
 	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/metrics"
+	"gitlab.com/therako/universal-studios/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
@@ -12,6 +16,10 @@ const (
 	TableName = "events"
 )
 
+// AggregateSourceAtIndex is the index of events on the columns EventFor looks up & sorts by, created
+// by migration 2
+const AggregateSourceAtIndex = "idx_events_aggregate_source_at"
+
 // EventInterface all events should adhere to this contract
 type EventInterface interface {
 	ToDBEvent() (event *Event, err error)
@@ -35,20 +43,69 @@ type DAO struct {
 }
 
 // Add adds the new event to DB
-func (r DAO) Add(event EventInterface) (err error) {
+func (r DAO) Add(ctx context.Context, event EventInterface) (err error) {
+	ctx, span := tracing.Start(ctx, "events.DAO.Add")
+	defer func() { tracing.End(span, err) }()
+
 	var e *Event
 	e, err = event.ToDBEvent()
 	if err != nil {
 		return
 	}
+	span.SetAttributes(attribute.String("event.aggregate", e.AggregateRoot), attribute.String("event.name", e.Name))
 
-	err = r.DB.Create(e).Error
+	err = r.DB.WithContext(ctx).Create(e).Error
+	if err == nil {
+		countAppended(ctx, e)
+	}
 	return
 }
 
+// appendedKey of the events added in a transaction, counted once it commits
+type appendedKey struct{}
+
+// Transaction runs fn in a transaction on the DB, the events added with the ctx fn is given are only
+// counted as appended once the transaction commits, or the one it's nested in does
+func (r DAO) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	appended := &[]*Event{}
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, appendedKey{}, appended), tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range *appended {
+		countAppended(ctx, e)
+	}
+	return nil
+}
+
+// countAppended counts the event as appended, or holds it till the transaction it's added in commits
+func countAppended(ctx context.Context, e *Event) {
+	if appended, ok := ctx.Value(appendedKey{}).(*[]*Event); ok {
+		*appended = append(*appended, e)
+		return
+	}
+	metrics.EventsAppended.WithLabelValues(e.AggregateRoot, e.Name).Inc()
+}
+
 // EventFor returns all events for a source ID for an aggregate sorted by event time (At)
-func (r DAO) EventFor(id uint, aggregate string) ([]*Event, error) {
+func (r DAO) EventFor(ctx context.Context, id uint, aggregate string) (events []*Event, err error) {
+	ctx, span := tracing.Start(ctx, "events.DAO.EventFor",
+		attribute.String("event.aggregate", aggregate), attribute.Int64("event.source_id", int64(id)))
+	defer func() {
+		span.SetAttributes(attribute.Int("events", len(events)))
+		tracing.End(span, err)
+	}()
+
+	events = []*Event{}
+	err = r.DB.WithContext(ctx).Table(TableName).Where("source_id = ? AND aggregate_root = ?", id, aggregate).Order("at asc").Find(&events).Error
+	return events, err
+}
+
+// Active returns events of an aggregate with the given name which haven't ended at the given time
+func (r DAO) Active(ctx context.Context, aggregate string, name string, at time.Time) ([]*Event, error) {
 	events := []*Event{}
-	err := r.DB.Table(TableName).Where("source_id = ? AND aggregate_root = ?", id, aggregate).Order("at asc").Find(&events).Error
+	err := r.DB.WithContext(ctx).Table(TableName).Where("aggregate_root = ? AND name = ? AND ends_at > ?", aggregate, name, at).Order("at asc").Find(&events).Error
 	return events, err
 }
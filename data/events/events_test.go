@@ -1,15 +1,20 @@
 package events_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gitlab.com/therako/universal-studios/data/drivers"
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
 	"gitlab.com/therako/universal-studios/data/events"
 	"gitlab.com/therako/universal-studios/data/models"
-	"gorm.io/driver/sqlite"
+	"gitlab.com/therako/universal-studios/metrics"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gotest.tools/v3/assert"
@@ -26,11 +31,17 @@ var (
 	)
 )
 
-func testDB(name string) *gorm.DB {
-	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{Logger: gormLogger})
-	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
-	gormDB.AutoMigrate(&events.Event{})
-	return gormDB
+// testDB returns an in memory SQLite DB with the events table
+func testDB(t *testing.T) *gorm.DB {
+	db, err := drivers.Open(drivers.SQLite, fmt.Sprintf("file:%s?mode=memory", t.Name()), drivers.Pool{MaxOpenConns: 1}, &gorm.Config{Logger: gormLogger})
+	assert.NilError(t, err)
+	assert.NilError(t, db.AutoMigrate(&events.Event{}))
+	return db
+}
+
+// forEachDriver runs the test against an empty events table on every driver that can run locally
+func forEachDriver(t *testing.T, test func(t *testing.T, db *gorm.DB)) {
+	driverstest.ForEach(t, []interface{}{&events.Event{}}, test)
 }
 
 type testEvent struct {
@@ -52,74 +63,108 @@ func (e *testEvent) ToDBEvent() (*events.Event, error) {
 }
 
 func TestAddEvent(t *testing.T) {
-	db := testDB(t.Name())
-	dao := events.DAO{DB: db}
-	event := &testEvent{
-		SourceID:      123,
-		AggregateRoot: "test",
-		At:            time.Now(),
-		testData:      []byte("event data bytes"),
-	}
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		dao := events.DAO{DB: db}
+		event := &testEvent{
+			SourceID:      123,
+			AggregateRoot: "test",
+			// MySQL keeps milliseconds
+			At:       time.Now().Truncate(time.Millisecond),
+			testData: []byte("event data bytes"),
+		}
 
-	err := dao.Add(event)
+		err := dao.Add(context.Background(), event)
 
-	assert.NilError(t, err)
-	eventInDB := &events.Event{}
-	db.Table(events.TableName).First(eventInDB)
-	assert.Equal(t, event.SourceID, eventInDB.SourceID)
-	assert.Equal(t, event.AggregateRoot, eventInDB.AggregateRoot)
-	assert.DeepEqual(t, event.At, eventInDB.At)
+		assert.NilError(t, err)
+		eventInDB := &events.Event{}
+		db.Table(events.TableName).First(eventInDB)
+		assert.Equal(t, event.SourceID, eventInDB.SourceID)
+		assert.Equal(t, event.AggregateRoot, eventInDB.AggregateRoot)
+		assert.DeepEqual(t, event.At, eventInDB.At)
+		assert.DeepEqual(t, event.testData, eventInDB.Data)
+	})
 }
 
 func TestEventsForSourceID(t *testing.T) {
 	t.Run("expected to return source events for the aggregate in increasing time order excluding ended events", func(t *testing.T) {
-		db := testDB(t.Name())
-		dao := events.DAO{DB: db}
-		testingSourceID := uint(123)
-		testingAggregate := "Customer"
-		eventTime := time.Now()
-		db.Create([]*events.Event{
-			{
-				SourceID:      testingSourceID,
-				AggregateRoot: testingAggregate,
-				Name:          "CustomerQueued",
-				At:            eventTime.Add(1 * time.Second),
-				EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
-				Data:          []byte("right source delayed event start"),
-			},
-			{
-				SourceID:      987,
-				AggregateRoot: testingAggregate,
-				Name:          "CustomerQueued",
-				At:            eventTime,
-				EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
-				Data:          []byte("wrong source event"),
-			},
-			{
-				SourceID:      testingSourceID,
-				AggregateRoot: "Ride",
-				Name:          "RideCustomerQueued",
-				At:            eventTime,
-				EndsAt:        models.TimeP(eventTime.Add(1 * time.Second)),
-				Data:          []byte("wrong aggregate event"),
-			},
-			{
-				SourceID:      testingSourceID,
-				AggregateRoot: testingAggregate,
-				Name:          "CustomerQueued",
-				At:            eventTime,
-				EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
-				Data:          []byte("right source happening now"),
-			},
+		forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+			dao := events.DAO{DB: db}
+			testingSourceID := uint(123)
+			testingAggregate := "Customer"
+			eventTime := time.Now().Truncate(time.Millisecond)
+			db.Create([]*events.Event{
+				{
+					SourceID:      testingSourceID,
+					AggregateRoot: testingAggregate,
+					Name:          "CustomerQueued",
+					At:            eventTime.Add(1 * time.Second),
+					EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
+					Data:          []byte("right source delayed event start"),
+				},
+				{
+					SourceID:      987,
+					AggregateRoot: testingAggregate,
+					Name:          "CustomerQueued",
+					At:            eventTime,
+					EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
+					Data:          []byte("wrong source event"),
+				},
+				{
+					SourceID:      testingSourceID,
+					AggregateRoot: "Ride",
+					Name:          "RideCustomerQueued",
+					At:            eventTime,
+					EndsAt:        models.TimeP(eventTime.Add(1 * time.Second)),
+					Data:          []byte("wrong aggregate event"),
+				},
+				{
+					SourceID:      testingSourceID,
+					AggregateRoot: testingAggregate,
+					Name:          "CustomerQueued",
+					At:            eventTime,
+					EndsAt:        models.TimeP(eventTime.Add(10 * time.Second)),
+					Data:          []byte("right source happening now"),
+				},
+			})
+
+			events, err := dao.EventFor(context.Background(), 123, "Customer")
+
+			assert.NilError(t, err)
+			assert.Equal(t, 2, len(events))
+			assert.Equal(t, "Customer", events[0].AggregateRoot)
+			assert.DeepEqual(t, eventTime, events[0].At)
+			assert.Equal(t, "Customer", events[1].AggregateRoot)
+			assert.DeepEqual(t, eventTime.Add(1*time.Second), events[1].At)
 		})
+	})
+}
 
-		events, err := dao.EventFor(123, "Customer")
+func TestAppendedEventsAreCountedOnCommit(t *testing.T) {
+	db := testDB(t)
+	dao := events.DAO{DB: db}
+	appended := func() float64 {
+		return testutil.ToFloat64(metrics.EventsAppended.WithLabelValues("counted", ""))
+	}
+	add := func(ctx context.Context, tx *gorm.DB) error {
+		return events.DAO{DB: tx}.Add(ctx, &testEvent{SourceID: 1, AggregateRoot: "counted", At: time.Now()})
+	}
 
-		assert.NilError(t, err)
-		assert.Equal(t, 2, len(events))
-		assert.Equal(t, "Customer", events[0].AggregateRoot)
-		assert.DeepEqual(t, eventTime, events[0].At)
-		assert.Equal(t, "Customer", events[1].AggregateRoot)
-		assert.DeepEqual(t, eventTime.Add(1*time.Second), events[1].At)
+	rollback := errors.New("rollback")
+	err := dao.Transaction(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		assert.NilError(t, add(ctx, tx))
+		return rollback
+	})
+	assert.Equal(t, rollback, err)
+	assert.Equal(t, float64(0), appended(), "expected rolled back events not to be counted")
+
+	err = dao.Transaction(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		assert.NilError(t, add(ctx, tx))
+		// Nested transactions are counted when the outer one commits
+		return events.DAO{DB: tx}.Transaction(ctx, add)
 	})
+	assert.NilError(t, err)
+	assert.Equal(t, float64(2), appended())
+
+	assert.NilError(t, add(context.Background(), db))
+	assert.Equal(t, float64(3), appended())
 }
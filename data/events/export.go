@@ -0,0 +1,193 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Export formats
+const (
+	// FormatJSONL writes an event as a JSON object on each line
+	FormatJSONL = "jsonl"
+	// FormatColumnar writes events in compressed row groups of columns, see columnar.go
+	FormatColumnar = "columnar"
+)
+
+// Errors
+var (
+	ErrUnknownFormat = errors.New("Unknown export format, expected jsonl or columnar")
+	ErrInvalidEvent  = errors.New("Invalid event")
+	ErrUnknownEvent  = errors.New("Unknown event")
+)
+
+// Encoder writes exported events, Close must be called to write the buffered events
+type Encoder interface {
+	Encode(event *Event) error
+	Close() error
+}
+
+// Decoder reads exported events, returning io.EOF after the last one
+type Decoder interface {
+	Decode() (*Event, error)
+}
+
+// NewEncoder returns an encoder of the format writing to w
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return jsonlEncoder{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case FormatColumnar:
+		return newColumnarEncoder(w), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// NewDecoder returns a decoder of the format reading from r
+func NewDecoder(format string, r io.Reader) (Decoder, error) {
+	switch format {
+	case FormatJSONL:
+		return jsonlDecoder{json.NewDecoder(bufio.NewReader(r))}, nil
+	case FormatColumnar:
+		return newColumnarDecoder(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type jsonlEncoder struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (e jsonlEncoder) Encode(event *Event) error {
+	return e.encoder.Encode(event)
+}
+
+func (e jsonlEncoder) Close() error {
+	return e.buffered.Flush()
+}
+
+type jsonlDecoder struct {
+	decoder *json.Decoder
+}
+
+func (d jsonlDecoder) Decode() (*Event, error) {
+	event := &Event{}
+	err := d.decoder.Decode(event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ExportFilter narrows down the exported events, zero values are ignored
+type ExportFilter struct {
+	AggregateRoot string
+	Name          string
+	// From & Till bound the event time (At), From inclusive & Till exclusive
+	From *time.Time
+	Till *time.Time
+}
+
+// Export encodes the events matching the filter in the order they were added, returning the
+// no of events exported
+func (r DAO) Export(ctx context.Context, filter ExportFilter, encoder Encoder) (count int, err error) {
+	query := r.DB.WithContext(ctx).Table(TableName).Order("id")
+	if filter.AggregateRoot != "" {
+		query = query.Where("aggregate_root = ?", filter.AggregateRoot)
+	}
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+	if filter.From != nil {
+		query = query.Where("at >= ?", *filter.From)
+	}
+	if filter.Till != nil {
+		query = query.Where("at < ?", *filter.Till)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event := &Event{}
+		err = r.DB.ScanRows(rows, event)
+		if err != nil {
+			return
+		}
+		err = encoder.Encode(event)
+		if err != nil {
+			return
+		}
+		count++
+	}
+	err = rows.Err()
+	return
+}
+
+// Validate checks the event has what replaying it needs
+func Validate(event *Event) error {
+	switch {
+	case event.AggregateRoot == "":
+		return fmt.Errorf("%w: aggregate_root is required", ErrInvalidEvent)
+	case event.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidEvent)
+	case event.SourceID == 0:
+		return fmt.Errorf("%w: source_id is required", ErrInvalidEvent)
+	case event.At.IsZero():
+		return fmt.Errorf("%w: at is required", ErrInvalidEvent)
+	case event.EndsAt != nil && event.EndsAt.Before(event.At):
+		return fmt.Errorf("%w: ends_at is before at", ErrInvalidEvent)
+	case !json.Valid(event.Data):
+		return fmt.Errorf("%w: data isn't JSON", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// Import validates the decoded events & appends them as new events in the order they're decoded,
+// all or none of them, returning the no of events imported. validate when set checks each event
+// further, eg. that it's a known event of it's aggregate.
+func (r DAO) Import(ctx context.Context, decoder Decoder, validate func(event *Event) error) (count int, err error) {
+	err = r.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		for {
+			event, err := decoder.Decode()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("event %d: %w", count+1, err)
+			}
+			err = Validate(event)
+			if err == nil && validate != nil {
+				err = validate(event)
+			}
+			if err != nil {
+				return fmt.Errorf("event %d: %w", count+1, err)
+			}
+
+			// Re-appended with a new ID, the times are kept
+			event.ID = 0
+			err = tx.Create(event).Error
+			if err != nil {
+				return fmt.Errorf("event %d: %w", count+1, err)
+			}
+			countAppended(ctx, event)
+			count++
+		}
+	})
+	if err != nil {
+		count = 0
+	}
+	return
+}
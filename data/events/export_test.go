@@ -0,0 +1,238 @@
+package events_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func testEvents(at time.Time) []*events.Event {
+	return []*events.Event{
+		{SourceID: 1, AggregateRoot: "Customer", Name: "CustomerQueued", At: at, EndsAt: models.TimeP(at.Add(time.Minute)), Data: []byte(`{"customer":{"id":1}}`)},
+		{SourceID: 7, AggregateRoot: "Ride", Name: "RideCustomerQueued", At: at.Add(time.Second), EndsAt: models.TimeP(at.Add(time.Minute)), Data: []byte(`{"ride":{"id":7}}`)},
+		{SourceID: 1, AggregateRoot: "Customer", Name: "CustomerUnQueued", At: at.Add(2 * time.Second), Data: []byte(`{"customer":{"id":1}}`)},
+		{SourceID: 7, AggregateRoot: "Ride", Name: "RideStatusChanged", At: at.Add(time.Hour), Data: []byte(`{"down":true}`)},
+	}
+}
+
+func assertSameEvents(t *testing.T, expected []*events.Event, actual []*events.Event) {
+	t.Helper()
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.Equal(t, expected[i].SourceID, actual[i].SourceID)
+		assert.Equal(t, expected[i].AggregateRoot, actual[i].AggregateRoot)
+		assert.Equal(t, expected[i].Name, actual[i].Name)
+		assert.Assert(t, expected[i].At.Equal(actual[i].At), "%v != %v", expected[i].At, actual[i].At)
+		assert.Equal(t, expected[i].EndsAt == nil, actual[i].EndsAt == nil)
+		if expected[i].EndsAt != nil {
+			assert.Assert(t, expected[i].EndsAt.Equal(*actual[i].EndsAt))
+		}
+		assert.DeepEqual(t, expected[i].Data, actual[i].Data)
+	}
+}
+
+func decodeAll(t *testing.T, decoder events.Decoder) []*events.Event {
+	t.Helper()
+	decoded := []*events.Event{}
+	for {
+		event, err := decoder.Decode()
+		if err == io.EOF {
+			return decoded
+		}
+		assert.NilError(t, err)
+		decoded = append(decoded, event)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []string{events.FormatJSONL, events.FormatColumnar} {
+		t.Run(format, func(t *testing.T) {
+			forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+				ctx := context.Background()
+				at := time.Now().Truncate(time.Millisecond)
+				added := testEvents(at)
+				for _, event := range added {
+					assert.NilError(t, db.Create(event).Error)
+				}
+				dao := events.DAO{DB: db}
+
+				export := &bytes.Buffer{}
+				encoder, err := events.NewEncoder(format, export)
+				assert.NilError(t, err)
+				count, err := dao.Export(ctx, events.ExportFilter{}, encoder)
+				assert.NilError(t, err)
+				assert.NilError(t, encoder.Close())
+				assert.Equal(t, len(added), count)
+
+				// Re-appended to an emptied table
+				assert.NilError(t, db.Migrator().DropTable(&events.Event{}))
+				assert.NilError(t, db.AutoMigrate(&events.Event{}))
+				decoder, err := events.NewDecoder(format, export)
+				assert.NilError(t, err)
+				count, err = dao.Import(ctx, decoder, nil)
+				assert.NilError(t, err)
+				assert.Equal(t, len(added), count)
+				imported := []*events.Event{}
+				assert.NilError(t, db.Table(events.TableName).Order("id").Find(&imported).Error)
+				assertSameEvents(t, added, imported)
+			})
+		})
+	}
+}
+
+func TestExportFilter(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		at := time.Now().Truncate(time.Millisecond)
+		added := testEvents(at)
+		for _, event := range added {
+			assert.NilError(t, db.Create(event).Error)
+		}
+		dao := events.DAO{DB: db}
+
+		for _, tc := range []struct {
+			name     string
+			filter   events.ExportFilter
+			expected []*events.Event
+		}{
+			{"aggregate root", events.ExportFilter{AggregateRoot: "Ride"}, []*events.Event{added[1], added[3]}},
+			{"name", events.ExportFilter{Name: "CustomerUnQueued"}, []*events.Event{added[2]}},
+			{"time range", events.ExportFilter{From: models.TimeP(at.Add(time.Second)), Till: models.TimeP(at.Add(time.Hour))}, []*events.Event{added[1], added[2]}},
+			{"all", events.ExportFilter{AggregateRoot: "Customer", Name: "CustomerQueued", From: &at}, []*events.Event{added[0]}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				export := &bytes.Buffer{}
+				encoder, err := events.NewEncoder(events.FormatJSONL, export)
+				assert.NilError(t, err)
+				_, err = dao.Export(context.Background(), tc.filter, encoder)
+				assert.NilError(t, err)
+				assert.NilError(t, encoder.Close())
+
+				decoder, err := events.NewDecoder(events.FormatJSONL, export)
+				assert.NilError(t, err)
+				assertSameEvents(t, tc.expected, decodeAll(t, decoder))
+			})
+		}
+	})
+}
+
+func TestColumnar(t *testing.T) {
+	t.Run("expected to round trip events across row groups", func(t *testing.T) {
+		at := time.Date(2021, 1, 2, 10, 0, 0, 123456789, time.UTC)
+		encoded := []*events.Event{}
+		for i := 0; i < 5000; i++ {
+			event := testEvents(at.Add(time.Duration(i) * time.Minute))[i%4]
+			event.ID = uint(i + 1)
+			event.CreatedAt = at
+			encoded = append(encoded, event)
+		}
+
+		columnar := &bytes.Buffer{}
+		encoder, err := events.NewEncoder(events.FormatColumnar, columnar)
+		assert.NilError(t, err)
+		for _, event := range encoded {
+			assert.NilError(t, encoder.Encode(event))
+		}
+		assert.NilError(t, encoder.Close())
+
+		jsonl := &bytes.Buffer{}
+		encoder, _ = events.NewEncoder(events.FormatJSONL, jsonl)
+		for _, event := range encoded {
+			encoder.Encode(event)
+		}
+		encoder.Close()
+		assert.Assert(t, columnar.Len()*10 < jsonl.Len(), "expected columnar to be compact, %d bytes vs %d as JSON lines", columnar.Len(), jsonl.Len())
+
+		decoder, err := events.NewDecoder(events.FormatColumnar, columnar)
+		assert.NilError(t, err)
+		decoded := decodeAll(t, decoder)
+		assertSameEvents(t, encoded, decoded)
+		assert.Equal(t, uint(5000), decoded[4999].ID)
+		assert.Assert(t, at.Equal(decoded[4999].CreatedAt))
+	})
+
+	t.Run("expected an empty export to have no events", func(t *testing.T) {
+		columnar := &bytes.Buffer{}
+		encoder, _ := events.NewEncoder(events.FormatColumnar, columnar)
+		assert.NilError(t, encoder.Close())
+
+		decoder, err := events.NewDecoder(events.FormatColumnar, columnar)
+		assert.NilError(t, err)
+		_, err = decoder.Decode()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("expected corrupt exports to fail", func(t *testing.T) {
+		_, err := events.NewDecoder(events.FormatColumnar, bytes.NewBufferString(`{"name":"CustomerQueued"}`))
+		assert.Equal(t, events.ErrInvalidColumnar, err)
+
+		columnar := &bytes.Buffer{}
+		encoder, _ := events.NewEncoder(events.FormatColumnar, columnar)
+		encoder.Encode(testEvents(time.Now())[0])
+		encoder.Close()
+		truncated := bytes.NewReader(columnar.Bytes()[:columnar.Len()-12])
+		decoder, err := events.NewDecoder(events.FormatColumnar, truncated)
+		if err == nil {
+			_, err = decoder.Decode()
+		}
+		assert.Assert(t, err != nil && err != io.EOF, "expected a truncated export to fail, got %v", err)
+	})
+
+	_, err := events.NewEncoder("parquet", &bytes.Buffer{})
+	assert.Equal(t, events.ErrUnknownFormat, err)
+}
+
+func TestImportValidation(t *testing.T) {
+	ctx := context.Background()
+	at := time.Now()
+
+	for _, tc := range []struct {
+		name     string
+		invalid  func(event *events.Event)
+		validate func(event *events.Event) error
+		expected error
+	}{
+		{"missing aggregate root", func(e *events.Event) { e.AggregateRoot = "" }, nil, events.ErrInvalidEvent},
+		{"missing source", func(e *events.Event) { e.SourceID = 0 }, nil, events.ErrInvalidEvent},
+		{"ending before it starts", func(e *events.Event) { e.EndsAt = models.TimeP(at.Add(-time.Hour)) }, nil, events.ErrInvalidEvent},
+		{"data not JSON", func(e *events.Event) { e.Data = []byte("event data bytes") }, nil, events.ErrInvalidEvent},
+		{"rejected by the validator", func(e *events.Event) { e.Name = "CustomerTeleported" }, func(e *events.Event) error {
+			if e.Name == "CustomerTeleported" {
+				return events.ErrUnknownEvent
+			}
+			return nil
+		}, events.ErrUnknownEvent},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := testDB(t)
+			dao := events.DAO{DB: db}
+			export := &bytes.Buffer{}
+			encoder, _ := events.NewEncoder(events.FormatJSONL, export)
+			for i, event := range testEvents(at) {
+				if i == 2 {
+					tc.invalid(event)
+				}
+				encoder.Encode(event)
+			}
+			encoder.Close()
+			decoder, _ := events.NewDecoder(events.FormatJSONL, export)
+
+			count, err := dao.Import(ctx, decoder, tc.validate)
+
+			assert.Assert(t, errors.Is(err, tc.expected), "got %v", err)
+			assert.ErrorContains(t, err, "event 3")
+			assert.Equal(t, 0, count)
+			var inDB int64
+			db.Table(events.TableName).Count(&inDB)
+			assert.Equal(t, int64(0), inDB, fmt.Sprintf("expected nothing to be imported, found %d", inDB))
+		})
+	}
+}
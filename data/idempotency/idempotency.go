@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"time"
+
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DB table names
+const (
+	TableName = "idempotency_keys"
+)
+
+// Response DB model for the first response sent for an idempotency key
+type Response struct {
+	models.Model
+	// Key is the client's idempotency key scoped to the route & caller
+	Key string `gorm:"column:idempotency_key;uniqueIndex" json:"key"`
+	// RequestHash of the request body, a key can't be reused with a different request
+	RequestHash string `gorm:"column:request_hash" json:"request_hash"`
+	Status      int    `gorm:"column:status" json:"status"`
+	ContentType string `gorm:"column:content_type" json:"content_type"`
+	Body        []byte `gorm:"column:body" json:"body"`
+}
+
+// TableName overrides gorm's default of `responses`
+func (Response) TableName() string {
+	return TableName
+}
+
+// DAO is data access object for stored responses
+type DAO struct {
+	DB *gorm.DB
+	// Clock tells when responses are stored, the real clock when nil
+	Clock clock.Clock
+}
+
+func (r DAO) now() time.Time {
+	return clock.Or(r.Clock).Now()
+}
+
+// Get returns the response stored for the key after the given time
+func (r DAO) Get(key string, since time.Time) (response *Response, err error) {
+	response = &Response{}
+	err = r.DB.Where("idempotency_key = ? AND created_at >= ?", key, since).First(response).Error
+	return
+}
+
+// Save stores the response, replacing one stored earlier for the same key
+func (r DAO) Save(response *Response) (err error) {
+	now := r.now()
+	response.CreatedAt, response.UpdatedAt = now, now
+	err = r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"created_at", "updated_at", "request_hash", "status", "content_type", "body"}),
+	}).Create(response).Error
+	return
+}
+
+// DeleteBefore removes responses stored before the given time, returning how many were removed
+func (r DAO) DeleteBefore(at time.Time) (int64, error) {
+	result := r.DB.Where("created_at < ?", at).Delete(&Response{})
+	return result.RowsAffected, result.Error
+}
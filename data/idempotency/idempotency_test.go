@@ -0,0 +1,54 @@
+package idempotency_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
+	"gitlab.com/therako/universal-studios/data/idempotency"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func TestSaveAndGet(t *testing.T) {
+	driverstest.ForEach(t, []interface{}{&idempotency.Response{}}, func(t *testing.T, db *gorm.DB) {
+		clock := clockwork.NewFakeClockAt(time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC))
+		dao := idempotency.DAO{DB: db, Clock: clock}
+
+		assert.NilError(t, dao.Save(&idempotency.Response{Key: "add-1", RequestHash: "a", Status: 201, Body: []byte(`{"id":1}`)}))
+		stored, err := dao.Get("add-1", clock.Now().Add(-time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, 201, stored.Status)
+		assert.Equal(t, `{"id":1}`, string(stored.Body))
+
+		// Saving the key again replaces the response & when it was stored
+		clock.Advance(time.Hour)
+		assert.NilError(t, dao.Save(&idempotency.Response{Key: "add-1", RequestHash: "b", Status: 409}))
+		stored, err = dao.Get("add-1", clock.Now().Add(-time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, "b", stored.RequestHash)
+		assert.Equal(t, 409, stored.Status)
+
+		_, err = dao.Get("add-1", clock.Now().Add(time.Second))
+		assert.Assert(t, errors.Is(err, gorm.ErrRecordNotFound), "expected responses before the window to be ignored")
+	})
+}
+
+func TestDeleteBefore(t *testing.T) {
+	driverstest.ForEach(t, []interface{}{&idempotency.Response{}}, func(t *testing.T, db *gorm.DB) {
+		clock := clockwork.NewFakeClockAt(time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC))
+		dao := idempotency.DAO{DB: db, Clock: clock}
+		assert.NilError(t, dao.Save(&idempotency.Response{Key: "old", Status: 200}))
+		clock.Advance(time.Hour)
+		assert.NilError(t, dao.Save(&idempotency.Response{Key: "new", Status: 200}))
+
+		removed, err := dao.DeleteBefore(clock.Now().Add(-time.Minute))
+
+		assert.NilError(t, err)
+		assert.Equal(t, int64(1), removed)
+		_, err = dao.Get("new", clock.Now().Add(-time.Minute))
+		assert.NilError(t, err)
+	})
+}
@@ -0,0 +1,210 @@
+// Package migrations versions the studio's DB schema, applying & rolling back ordered migrations
+// & recording the applied ones in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/logging"
+	"gorm.io/gorm"
+)
+
+// DB table names
+const (
+	TableName = "schema_migrations"
+)
+
+// Errors
+var (
+	ErrUnorderedMigrations = errors.New("Migration versions must be unique & increasing")
+	ErrUnknownVersion      = errors.New("Unknown migration version")
+)
+
+// Migration changes the schema from the previous version to Version & back
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Applied DB model of a migration applied to the DB
+type Applied struct {
+	Version   uint      `gorm:"column:version;primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"column:name" json:"name"`
+	AppliedAt time.Time `gorm:"column:applied_at" json:"applied_at"`
+}
+
+// TableName overrides gorm's default of `applieds`
+func (Applied) TableName() string {
+	return TableName
+}
+
+// All migrations of the studio in order, released migrations must not be changed, add a new one instead
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		// The tables as AutoMigrate created them on start up before migrations were versioned, it
+		// only adds what's missing so it's safe to apply on those DBs
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&rideV1{}, &customerV1{}, &partyV1{}, &ticketV1{}, &eventV1{}, &idempotencyResponseV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&idempotencyResponseV1{}, &eventV1{}, &customerV1{}, &partyV1{}, &ticketV1{}, &rideV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "index_events_aggregate_source_at",
+		// Used by events.DAO.EventFor to replay the events of a customer or ride
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateIndex(&eventV2{}, events.AggregateSourceAtIndex)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropIndex(&eventV2{}, events.AggregateSourceAtIndex)
+		},
+	},
+}
+
+// Status of a migration, AppliedAt is nil while it's pending
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies & rolls back the migrations on the DB
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// New returns a migrator of all the studio's migrations
+func New(db *gorm.DB) Migrator {
+	return Migrator{DB: db, Migrations: All}
+}
+
+// Up applies the pending migrations up to & including version `to`, all of them when `to` is 0,
+// returning the ones applied. Each migration is applied in a transaction along with it's record,
+// though MySQL commits schema changes right away.
+func (m Migrator) Up(ctx context.Context, to uint) (applied []Migration, err error) {
+	if to != 0 && !m.known(to) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, to)
+	}
+	done, err := m.applied(ctx)
+	if err != nil {
+		return
+	}
+	for _, migration := range m.Migrations {
+		if to != 0 && migration.Version > to {
+			break
+		}
+		if _, ok := done[migration.Version]; ok {
+			continue
+		}
+		err = m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := migration.Up(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&Applied{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		logging.FromContext(ctx).Info("Migration applied", "version", migration.Version, "name", migration.Name)
+		applied = append(applied, migration)
+	}
+	return
+}
+
+// Down rolls back the last `steps` applied migrations, returning the ones rolled back
+func (m Migrator) Down(ctx context.Context, steps int) (rolledBack []Migration, err error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return
+	}
+	for i := len(m.Migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		migration := m.Migrations[i]
+		if _, ok := done[migration.Version]; !ok {
+			continue
+		}
+		err = m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := migration.Down(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Delete(&Applied{}, migration.Version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		logging.FromContext(ctx).Info("Migration rolled back", "version", migration.Version, "name", migration.Name)
+		rolledBack = append(rolledBack, migration)
+	}
+	return
+}
+
+// Status returns every migration in order along with when it was applied
+func (m Migrator) Status(ctx context.Context) (statuses []Status, err error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return
+	}
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+		if applied, ok := done[migration.Version]; ok {
+			status.AppliedAt = &applied.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return
+}
+
+// Version returns the latest applied migration version, 0 on an empty DB
+func (m Migrator) Version(ctx context.Context) (version uint, err error) {
+	done, err := m.applied(ctx)
+	for v := range done {
+		if v > version {
+			version = v
+		}
+	}
+	return
+}
+
+// applied returns the applied migrations by version, creating the migrations table if missing
+func (m Migrator) applied(ctx context.Context) (done map[uint]Applied, err error) {
+	for i := 1; i < len(m.Migrations); i++ {
+		if m.Migrations[i].Version <= m.Migrations[i-1].Version {
+			return nil, ErrUnorderedMigrations
+		}
+	}
+	db := m.DB.WithContext(ctx)
+	err = db.AutoMigrate(&Applied{})
+	if err != nil {
+		return
+	}
+	rows := []Applied{}
+	err = db.Order("version").Find(&rows).Error
+	if err != nil {
+		return
+	}
+	done = map[uint]Applied{}
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return
+}
+
+func (m Migrator) known(version uint) bool {
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
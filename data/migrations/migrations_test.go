@@ -0,0 +1,109 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/migrations"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func testDB(name string) *gorm.DB {
+	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{})
+	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
+	return gormDB
+}
+
+func versions(migrations []migrations.Migration) []uint {
+	versions := []uint{}
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t.Name())
+	migrator := migrations.New(db)
+
+	applied, err := migrator.Up(ctx, 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []uint{1}, versions(applied))
+	assert.Assert(t, db.Migrator().HasTable(&rides.Ride{}))
+	assert.Assert(t, !db.Migrator().HasIndex(&events.Event{}, events.AggregateSourceAtIndex), "expected the index to be left to migration 2")
+
+	applied, err = migrator.Up(ctx, 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []uint{2}, versions(applied))
+	assert.Assert(t, db.Migrator().HasIndex(&events.Event{}, events.AggregateSourceAtIndex))
+	version, err := migrator.Version(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(2), version)
+
+	applied, err = migrator.Up(ctx, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(applied), "expected applied migrations to be skipped")
+
+	rolledBack, err := migrator.Down(ctx, 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []uint{2}, versions(rolledBack))
+	assert.Assert(t, !db.Migrator().HasIndex(&events.Event{}, events.AggregateSourceAtIndex))
+	assert.Assert(t, db.Migrator().HasTable(&events.Event{}))
+	statuses, err := migrator.Status(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(statuses))
+	assert.Assert(t, statuses[0].AppliedAt != nil)
+	assert.Assert(t, statuses[1].AppliedAt == nil)
+
+	rolledBack, err = migrator.Down(ctx, 5)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []uint{1}, versions(rolledBack))
+	assert.Assert(t, !db.Migrator().HasTable(&rides.Ride{}))
+	version, err = migrator.Version(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(0), version)
+}
+
+func TestMigrateAutoMigratedDB(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t.Name())
+	// Tables as created before migrations were versioned, without the events index
+	assert.NilError(t, db.Exec("CREATE TABLE events (id integer PRIMARY KEY, created_at datetime, updated_at datetime, deleted_at datetime, source_id integer, at datetime, ends_at datetime, aggregate_root text, name text, data blob)").Error)
+
+	applied, err := migrations.New(db).Up(ctx, 0)
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []uint{1, 2}, versions(applied))
+	assert.Assert(t, db.Migrator().HasIndex(&events.Event{}, events.AggregateSourceAtIndex))
+}
+
+func TestMigrateErrors(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t.Name())
+
+	_, err := migrations.New(db).Up(ctx, 99)
+	assert.Assert(t, errors.Is(err, migrations.ErrUnknownVersion))
+
+	failing := errors.New("failing")
+	migrator := migrations.Migrator{DB: db, Migrations: []migrations.Migration{
+		{Version: 1, Name: "ok", Up: func(tx *gorm.DB) error { return nil }},
+		{Version: 2, Name: "failing", Up: func(tx *gorm.DB) error { return failing }},
+	}}
+	applied, err := migrator.Up(ctx, 0)
+	assert.Assert(t, errors.Is(err, failing))
+	assert.DeepEqual(t, []uint{1}, versions(applied))
+	version, err := migrator.Version(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(1), version, "expected the failed migration not to be recorded")
+
+	migrator.Migrations[0], migrator.Migrations[1] = migrator.Migrations[1], migrator.Migrations[0]
+	_, err = migrator.Up(ctx, 0)
+	assert.Equal(t, migrations.ErrUnorderedMigrations, err)
+}
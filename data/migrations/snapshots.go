@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"time"
+)
+
+// Snapshots of the models as each migration changed them. They're frozen so released migrations
+// don't change along with the models, later schema changes are new migrations with new snapshots.
+
+type modelV1 struct {
+	ID        uint       `gorm:"primary_key;column:id"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at"`
+	DeletedAt *time.Time `gorm:"column:deleted_at"`
+}
+
+type rideV1 struct {
+	Model              modelV1 `gorm:"embedded"`
+	Name               string  `gorm:"column:name"`
+	Desc               string  `gorm:"column:desc"`
+	RideTime           int64   `gorm:"column:ride_time"`
+	Capacity           uint    `gorm:"column:capacity"`
+	Zone               string  `gorm:"column:zone"`
+	MinHeightCm        uint    `gorm:"column:min_height_cm"`
+	MinAge             uint    `gorm:"column:min_age"`
+	ThrillLevel        uint    `gorm:"column:thrill_level"`
+	Accessibility      string  `gorm:"column:accessibility;type:text"`
+	Tags               string  `gorm:"column:tags;type:text"`
+	QueueTypes         string  `gorm:"column:queue_types;type:text"`
+	PriorityMergeRatio uint    `gorm:"column:priority_merge_ratio"`
+	// Calculated from the ride's state, AutoMigrate created the columns all the same
+	EstimatedWaitingTime int64 `gorm:"column:estimated_waiting_time"`
+	InQueue              uint  `gorm:"column:in_queue"`
+}
+
+func (rideV1) TableName() string {
+	return "rides"
+}
+
+type customerV1 struct {
+	Model    modelV1    `gorm:"embedded"`
+	ExitAt   *time.Time `gorm:"column:exit_at"`
+	PartyID  *uint      `gorm:"column:party_id"`
+	TicketID *uint      `gorm:"column:ticket_id"`
+}
+
+func (customerV1) TableName() string {
+	return "customers"
+}
+
+type partyV1 struct {
+	Model   modelV1       `gorm:"embedded"`
+	Name    string        `gorm:"column:name"`
+	Members []*customerV1 `gorm:"foreignKey:PartyID"`
+}
+
+func (partyV1) TableName() string {
+	return "parties"
+}
+
+type ticketV1 struct {
+	Model      modelV1    `gorm:"embedded"`
+	Code       string     `gorm:"column:code;uniqueIndex:idx_tickets_code"`
+	Type       string     `gorm:"column:type"`
+	ValidFrom  time.Time  `gorm:"column:valid_from"`
+	ValidTill  time.Time  `gorm:"column:valid_till"`
+	ReEntry    string     `gorm:"column:re_entry"`
+	ScanCount  uint       `gorm:"column:scan_count"`
+	LastScanAt *time.Time `gorm:"column:last_scan_at"`
+}
+
+func (ticketV1) TableName() string {
+	return "tickets"
+}
+
+type eventV1 struct {
+	Model         modelV1    `gorm:"embedded"`
+	SourceID      uint       `gorm:"column:source_id"`
+	At            time.Time  `gorm:"column:at"`
+	EndsAt        *time.Time `gorm:"column:ends_at"`
+	AggregateRoot string     `gorm:"column:aggregate_root"`
+	Name          string     `gorm:"column:name"`
+	Data          []byte     `gorm:"column:data"`
+}
+
+func (eventV1) TableName() string {
+	return "events"
+}
+
+type idempotencyResponseV1 struct {
+	Model       modelV1 `gorm:"embedded"`
+	Key         string  `gorm:"column:idempotency_key;uniqueIndex:idx_idempotency_keys_key"`
+	RequestHash string  `gorm:"column:request_hash"`
+	Status      int     `gorm:"column:status"`
+	ContentType string  `gorm:"column:content_type"`
+	Body        []byte  `gorm:"column:body"`
+}
+
+func (idempotencyResponseV1) TableName() string {
+	return "idempotency_keys"
+}
+
+// eventV2 has the index events.DAO.EventFor looks up & sorts by
+type eventV2 struct {
+	SourceID      uint      `gorm:"column:source_id;index:idx_events_aggregate_source_at,priority:2"`
+	At            time.Time `gorm:"column:at;index:idx_events_aggregate_source_at,priority:3"`
+	AggregateRoot string    `gorm:"column:aggregate_root;index:idx_events_aggregate_source_at,priority:1"`
+}
+
+func (eventV2) TableName() string {
+	return "events"
+}
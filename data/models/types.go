@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StringList is a list of strings stored as a JSON array in a text column
+type StringList []string
+
+// GormDataType is the column type used on migrations
+func (StringList) GormDataType() string {
+	return "text"
+}
+
+// Value stores the list as JSON, empty lists are stored as NULL
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+// Scan reads the JSON array stored by Value
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case []byte:
+		return json.Unmarshal(v, l)
+	default:
+		return fmt.Errorf("unsupported type %T for StringList", value)
+	}
+}
+
+// Contains checks if the value is in the list
+func (l StringList) Contains(value string) bool {
+	for _, v := range l {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LikeEscape is the escape character of ElementPatterns, queried with `LIKE ? ESCAPE '!'`
+const LikeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+
+// ElementPatterns returns the LIKE patterns matching StringList columns with the value as their
+// first or a later element, a column has the value when it matches either. The value is JSON encoded
+// like Value does & it's wildcards escaped, so `%`, `_` or `"` in it only match themselves. Anchoring
+// on the `[` or `,` before the element keeps it from matching the end of another element, as `"`
+// inside an element is always escaped.
+func ElementPatterns(value string) (first, later string) {
+	data, _ := json.Marshal(value)
+	element := likeEscaper.Replace(string(data)) + "%"
+	return "[" + element, "%," + element
+}
@@ -0,0 +1,91 @@
+package parties
+
+import (
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/domain"
+	"gorm.io/gorm"
+)
+
+// DB table names
+const (
+	TableName = "parties"
+)
+
+// Errors
+var (
+	ErrPartyHasNoMembers    = domain.NewError(domain.Invalid, "party_has_no_members", "Party needs atleast one member")
+	ErrMemberInAnotherParty = domain.NewError(domain.Conflict, "member_in_another_party", "Customer is already in another party")
+)
+
+// Party DB model for a group of customers who queue together, eg. families
+type Party struct {
+	models.Model
+	Name    string                `gorm:"column:name" json:"name"`
+	Members []*customers.Customer `gorm:"foreignKey:PartyID" json:"members"`
+}
+
+// Size returns the no of customers in the party
+func (p *Party) Size() uint {
+	return uint(len(p.Members))
+}
+
+// DAO is data access object for parties
+type DAO struct {
+	DB *gorm.DB
+}
+
+// Create adds a new party with the given customers as members, customers can only be in one party
+func (r DAO) Create(name string, memberIDs []uint) (party *Party, err error) {
+	unique := map[uint]bool{}
+	ids := []uint{}
+	for _, id := range memberIDs {
+		if !unique[id] {
+			unique[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrPartyHasNoMembers
+	}
+
+	party = &Party{Name: name}
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(party).Error
+		if err != nil {
+			return err
+		}
+
+		// Only customers outside a party are moved, so concurrent parties can't take the same member
+		result := tx.Table(customers.TableName).Where("id IN ? AND party_id IS NULL", ids).Update("party_id", party.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == int64(len(ids)) {
+			return nil
+		}
+
+		var inParty int64
+		err = tx.Table(customers.TableName).Where("id IN ? AND party_id <> ?", ids, party.ID).Count(&inParty).Error
+		if err != nil {
+			return err
+		}
+		if inParty > 0 {
+			return ErrMemberInAnotherParty
+		}
+		// Some of the members don't exist
+		return gorm.ErrRecordNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(party.ID)
+}
+
+// Get returns the party along with its members
+func (r DAO) Get(id uint) (party *Party, err error) {
+	party = &Party{Model: models.Model{ID: id}}
+	err = r.DB.Preload("Members").First(party).Error
+	return
+}
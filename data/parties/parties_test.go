@@ -0,0 +1,83 @@
+package parties_test
+
+import (
+	"errors"
+	"testing"
+
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
+	"gitlab.com/therako/universal-studios/data/parties"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func forEachDriver(t *testing.T, test func(t *testing.T, db *gorm.DB, dao parties.DAO)) {
+	driverstest.ForEach(t, []interface{}{&parties.Party{}, &customers.Customer{}}, func(t *testing.T, db *gorm.DB) {
+		test(t, db, parties.DAO{DB: db})
+	})
+}
+
+func TestCreate(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB, dao parties.DAO) {
+		for i := 0; i < 3; i++ {
+			assert.NilError(t, db.Create(&customers.Customer{}).Error)
+		}
+
+		party, err := dao.Create("Smiths", []uint{1, 2, 2})
+
+		assert.NilError(t, err)
+		assert.Equal(t, "Smiths", party.Name)
+		assert.Equal(t, uint(2), party.Size(), "expected members to be unique")
+		stored, err := dao.Get(party.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, uint(2), stored.Size())
+		assert.Equal(t, party.ID, *stored.Members[0].PartyID)
+	})
+}
+
+func TestCreateWithUnknownMembers(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB, dao parties.DAO) {
+		assert.NilError(t, db.Create(&customers.Customer{}).Error)
+
+		_, err := dao.Create("Smiths", []uint{1, 99})
+
+		assert.Assert(t, errors.Is(err, gorm.ErrRecordNotFound))
+		var count int64
+		assert.NilError(t, db.Model(&parties.Party{}).Count(&count).Error)
+		assert.Equal(t, int64(0), count, "expected the party to be rolled back")
+		member := &customers.Customer{}
+		assert.NilError(t, db.First(member, 1).Error)
+		assert.Assert(t, member.PartyID == nil)
+	})
+}
+
+func TestCreateWithoutMembers(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB, dao parties.DAO) {
+		_, err := dao.Create("Smiths", []uint{})
+
+		assert.Equal(t, parties.ErrPartyHasNoMembers, err)
+		var count int64
+		assert.NilError(t, db.Model(&parties.Party{}).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestCreateWithMembersOfAnotherParty(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB, dao parties.DAO) {
+		for i := 0; i < 3; i++ {
+			assert.NilError(t, db.Create(&customers.Customer{}).Error)
+		}
+		smiths, err := dao.Create("Smiths", []uint{1, 2})
+		assert.NilError(t, err)
+
+		_, err = dao.Create("Joneses", []uint{2, 3})
+
+		assert.Equal(t, parties.ErrMemberInAnotherParty, err)
+		stored, err := dao.Get(smiths.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, uint(2), stored.Size(), "expected the existing party to keep its members")
+		member := &customers.Customer{}
+		assert.NilError(t, db.First(member, 3).Error)
+		assert.Assert(t, member.PartyID == nil, "expected the new party to be rolled back")
+	})
+}
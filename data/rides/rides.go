@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DB table names
@@ -20,9 +22,29 @@ type Ride struct {
 	RideTime time.Duration `gorm:"column:ride_time" json:"ride_time_in_ns"`
 	Capacity uint          `gorm:"column:capacity" json:"capacity"`
 
+	// Metadata used by guests to find rides
+	Zone          string            `gorm:"column:zone" json:"zone"`
+	MinHeightCm   uint              `gorm:"column:min_height_cm" json:"min_height_cm"`
+	MinAge        uint              `gorm:"column:min_age" json:"min_age"`
+	ThrillLevel   uint              `gorm:"column:thrill_level" json:"thrill_level"`
+	Accessibility models.StringList `gorm:"column:accessibility" json:"accessibility"`
+	Tags          models.StringList `gorm:"column:tags" json:"tags"`
+	// QueueTypes other than standby enabled for the ride, eg. single_rider or virtual
+	QueueTypes models.StringList `gorm:"column:queue_types" json:"queue_types"`
+	// PriorityMergeRatio is how many priority customers board in each batch along with standby
+	PriorityMergeRatio uint `gorm:"column:priority_merge_ratio" json:"priority_merge_ratio"`
+
 	// Calcualted from state not in DB
 	EstimatedWaitingTime time.Duration `json:"waiting_time_in_ns"`
 	InQueue              uint          `json:"in_queue_count"`
+	// Waiting time & queue count of queue types other than standby
+	QueueWaitingTimes map[string]time.Duration `gorm:"-" json:"queue_waiting_times_in_ns,omitempty"`
+	QueueInCounts     map[string]uint          `gorm:"-" json:"queue_in_counts,omitempty"`
+}
+
+// IsRetired is true once the ride is soft deleted
+func (r *Ride) IsRetired() bool {
+	return r.DeletedAt != nil
 }
 
 // DAO is data access object for rides
@@ -30,13 +52,93 @@ type DAO struct {
 	DB *gorm.DB
 }
 
-// List returns a list of studio rides
-func (r DAO) List() (rides []*Ride, err error) {
-	err = r.DB.Table(TableName).Scan(&rides).Error
+// Sort orders the list of rides
+type Sort string
+
+// Sort orders
+const (
+	SortByID   Sort = ""
+	SortByName Sort = "name"
+	// SortByWait orders by the estimated waiting time, which comes from the ride's events
+	// so it's only applied once the ride states are known, see events/rides.List
+	SortByWait Sort = "wait"
+)
+
+// Errors
+var (
+	ErrUnknownSort     = domain.NewError(domain.Invalid, "unknown_sort", "Unknown sort, expected name or wait")
+	ErrInvalidPageSize = domain.NewError(domain.Invalid, "invalid_page_size", "Page size must be between 1 & 1000")
+)
+
+// MaxPageSize is the most rides listed in a page
+const MaxPageSize = 1000
+
+// Filter narrows down the list of rides, zero values are ignored
+type Filter struct {
+	Zone           string
+	Tag            string
+	Accessibility  string
+	MaxThrillLevel uint
+	// Guest's height & age, only rides the guest is allowed on are returned
+	HeightCm uint
+	Age      uint
+	// MaxWait like SortByWait needs the ride states, it's ignored by the DAO
+	MaxWait *time.Duration
+
+	// Retired lists only the retired rides instead of the ones in service
+	Retired bool
+
+	Sort Sort
+	Desc bool
+}
+
+// List returns a list of studio rides matching the filter
+func (r DAO) List(filter Filter) (rides []*Ride, err error) {
+	query := r.DB.Table(TableName)
+	if filter.Retired {
+		query = query.Where("deleted_at IS NOT NULL")
+	} else {
+		query = query.Where("deleted_at IS NULL")
+	}
+	if filter.Zone != "" {
+		query = query.Where("zone = ?", filter.Zone)
+	}
+	if filter.Tag != "" {
+		query = hasElement(query, "tags", filter.Tag)
+	}
+	if filter.Accessibility != "" {
+		query = hasElement(query, "accessibility", filter.Accessibility)
+	}
+	if filter.MaxThrillLevel != 0 {
+		query = query.Where("thrill_level <= ?", filter.MaxThrillLevel)
+	}
+	if filter.HeightCm != 0 {
+		query = query.Where("min_height_cm <= ?", filter.HeightCm)
+	}
+	if filter.Age != 0 {
+		query = query.Where("min_age <= ?", filter.Age)
+	}
+	switch filter.Sort {
+	case SortByID, SortByWait:
+		// Waits are sorted later, by id keeps the order stable for equal waits
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: filter.Desc && filter.Sort == SortByID})
+	case SortByName:
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "name"}, Desc: filter.Desc}).Order("id")
+	default:
+		return nil, ErrUnknownSort
+	}
+	err = query.Scan(&rides).Error
 	return
 }
 
-// Get returns a single studio ride
+// hasElement narrows the query to rows with the value in the StringList column
+func hasElement(query *gorm.DB, column, value string) *gorm.DB {
+	like := column + " LIKE ? ESCAPE '" + models.LikeEscape + "'"
+	first, later := models.ElementPatterns(value)
+	return query.Where("("+like+" OR "+like+")", first, later)
+}
+
+// Get returns a single studio ride, retired rides included
 func (r DAO) Get(id uint) (ride *Ride, err error) {
 	ride = &Ride{Model: models.Model{ID: id}}
 	err = r.DB.Table(TableName).First(ride).Error
@@ -48,3 +150,21 @@ func (r DAO) Add(ride *Ride) (err error) {
 	err = r.DB.Create(&ride).Error
 	return
 }
+
+// Retire soft deletes the ride, it's no longer listed but it's events & history are kept
+func (r DAO) Retire(ride *Ride, at time.Time) (err error) {
+	err = r.DB.Model(ride).Update("deleted_at", at).Error
+	return
+}
+
+// Restore brings a retired ride back into service
+func (r DAO) Restore(ride *Ride) (err error) {
+	err = r.DB.Model(ride).Update("deleted_at", nil).Error
+	return
+}
+
+// Update saves the changes to an existing ride
+func (r DAO) Update(ride *Ride) (err error) {
+	err = r.DB.Save(ride).Error
+	return
+}
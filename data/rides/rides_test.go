@@ -0,0 +1,103 @@
+package rides_test
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/data/rides"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func forEachDriver(t *testing.T, test func(t *testing.T, dao rides.DAO)) {
+	driverstest.ForEach(t, []interface{}{&rides.Ride{}}, func(t *testing.T, db *gorm.DB) {
+		test(t, rides.DAO{DB: db})
+	})
+}
+
+func names(list []*rides.Ride) []string {
+	result := []string{}
+	for _, ride := range list {
+		result = append(result, ride.Name)
+	}
+	return result
+}
+
+func TestListFilters(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, dao rides.DAO) {
+		for _, ride := range []*rides.Ride{
+			{Name: "Mummy", Zone: "egypt", ThrillLevel: 4, MinHeightCm: 120, MinAge: 10, Tags: models.StringList{"dark", "indoor"}, Accessibility: models.StringList{"wheelchair"}},
+			{Name: "Carousel", Zone: "kids", ThrillLevel: 1, Tags: models.StringList{"indoor_kids"}, Accessibility: models.StringList{"wheelchair_transfer"}},
+			{Name: "Drop", Zone: "kids", ThrillLevel: 3, MinHeightCm: 100, Tags: models.StringList{"100%_fun"}},
+		} {
+			assert.NilError(t, dao.Add(ride))
+		}
+
+		for name, test := range map[string]struct {
+			filter   rides.Filter
+			expected []string
+		}{
+			"all":                              {rides.Filter{}, []string{"Mummy", "Carousel", "Drop"}},
+			"zone":                             {rides.Filter{Zone: "kids"}, []string{"Carousel", "Drop"}},
+			"tag as a whole element":           {rides.Filter{Tag: "indoor"}, []string{"Mummy"}},
+			"tag with LIKE wildcards":          {rides.Filter{Tag: "100%_fun"}, []string{"Drop"}},
+			"wildcards matching only themself": {rides.Filter{Tag: "indoor%"}, []string{}},
+			"accessibility":                    {rides.Filter{Accessibility: "wheelchair"}, []string{"Mummy"}},
+			"max thrill level":                 {rides.Filter{MaxThrillLevel: 3}, []string{"Carousel", "Drop"}},
+			"guest height & age":               {rides.Filter{HeightCm: 110, Age: 8}, []string{"Carousel", "Drop"}},
+			"by name":                          {rides.Filter{Sort: rides.SortByName}, []string{"Carousel", "Drop", "Mummy"}},
+			"by name descending":               {rides.Filter{Sort: rides.SortByName, Desc: true}, []string{"Mummy", "Drop", "Carousel"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				list, err := dao.List(test.filter)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, test.expected, names(list))
+			})
+		}
+
+		_, err := dao.List(rides.Filter{Sort: "capacity"})
+		assert.Equal(t, rides.ErrUnknownSort, err)
+	})
+}
+
+func TestRetireAndRestore(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, dao rides.DAO) {
+		ride := &rides.Ride{Name: "Jaws", Capacity: 8, RideTime: 5 * time.Minute}
+		assert.NilError(t, dao.Add(ride))
+
+		assert.NilError(t, dao.Retire(ride, time.Now()))
+		list, err := dao.List(rides.Filter{})
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(list))
+		list, err = dao.List(rides.Filter{Retired: true})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string{"Jaws"}, names(list))
+		retired, err := dao.Get(ride.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, retired.IsRetired())
+
+		assert.NilError(t, dao.Restore(ride))
+		restored, err := dao.Get(ride.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, !restored.IsRetired())
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, dao rides.DAO) {
+		ride := &rides.Ride{Name: "Jaws", Capacity: 8, RideTime: 5 * time.Minute}
+		assert.NilError(t, dao.Add(ride))
+
+		ride.Capacity = 12
+		ride.QueueTypes = models.StringList{"single_rider"}
+		assert.NilError(t, dao.Update(ride))
+
+		updated, err := dao.Get(ride.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, uint(12), updated.Capacity)
+		assert.DeepEqual(t, models.StringList{"single_rider"}, updated.QueueTypes)
+		assert.Equal(t, 5*time.Minute, updated.RideTime)
+	})
+}
@@ -0,0 +1,137 @@
+package tickets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/models"
+	"gitlab.com/therako/universal-studios/domain"
+	"gorm.io/gorm"
+)
+
+// DB table names
+const (
+	TableName = "tickets"
+)
+
+// ReEntryPolicy defines if a customer can come back into the studio after exiting on a day the
+// ticket is valid, the first entry of each day is always allowed
+type ReEntryPolicy string
+
+// Re-entry policies
+const (
+	NoReEntry        ReEntryPolicy = "none"
+	SameDayReEntry   ReEntryPolicy = "same_day"
+	UnlimitedReEntry ReEntryPolicy = "unlimited"
+)
+
+// Errors
+var (
+	ErrTicketNotValidYet       = domain.NewError(domain.Forbidden, "ticket_not_valid_yet", "Ticket is not valid yet")
+	ErrTicketExpired           = domain.NewError(domain.Gone, "ticket_expired", "Ticket has expired")
+	ErrTicketReEntryNotAllowed = domain.NewError(domain.Forbidden, "ticket_re_entry_not_allowed", "Ticket doesn't allow re-entry")
+	ErrUnknownReEntryPolicy    = domain.NewError(domain.Invalid, "unknown_re_entry_policy", "Unknown re-entry policy")
+	ErrTicketScanConflict      = domain.NewError(domain.Conflict, "ticket_scan_conflict", "Ticket was scanned at the same time at another gate")
+)
+
+// Ticket DB model for entry tickets to the studio
+type Ticket struct {
+	models.Model
+	Code string `gorm:"column:code;uniqueIndex" json:"code"`
+	// Type of the ticket, eg. day, multi_day or annual
+	Type      string    `gorm:"column:type" json:"type"`
+	ValidFrom time.Time `gorm:"column:valid_from" json:"valid_from"`
+	// ValidTill is exclusive, the ticket can't be used from this time on
+	ValidTill  time.Time     `gorm:"column:valid_till" json:"valid_till"`
+	ReEntry    ReEntryPolicy `gorm:"column:re_entry" json:"re_entry"`
+	ScanCount  uint          `gorm:"column:scan_count" json:"scan_count"`
+	LastScanAt *time.Time    `gorm:"column:last_scan_at" json:"last_scan_at"`
+}
+
+// ValidateEntry checks if the ticket can be used to enter the studio at the given time. The
+// re-entry policy applies per day, so multi day & annual tickets let customers in on each day.
+func (t *Ticket) ValidateEntry(at time.Time) error {
+	if at.Before(t.ValidFrom) {
+		return ErrTicketNotValidYet
+	}
+	if !at.Before(t.ValidTill) {
+		return ErrTicketExpired
+	}
+	if t.ScanCount == 0 || t.LastScanAt == nil {
+		return nil
+	}
+	y1, m1, d1 := t.LastScanAt.In(at.Location()).Date()
+	y2, m2, d2 := at.Date()
+	if y1 != y2 || m1 != m2 || d1 != d2 {
+		// First entry of the day
+		return nil
+	}
+
+	switch t.ReEntry {
+	case SameDayReEntry, UnlimitedReEntry:
+		return nil
+	default:
+		return ErrTicketReEntryNotAllowed
+	}
+}
+
+// DAO is data access object for tickets
+type DAO struct {
+	DB *gorm.DB
+}
+
+// Add adds a new ticket, a random code is generated when it's missing
+func (r DAO) Add(ticket *Ticket) (err error) {
+	switch ticket.ReEntry {
+	case "":
+		ticket.ReEntry = NoReEntry
+	case NoReEntry, SameDayReEntry, UnlimitedReEntry:
+	default:
+		return ErrUnknownReEntryPolicy
+	}
+
+	if ticket.Code == "" {
+		ticket.Code, err = newCode()
+		if err != nil {
+			return
+		}
+	}
+
+	err = r.DB.Create(ticket).Error
+	return
+}
+
+// GetByCode returns the ticket with the code
+func (r DAO) GetByCode(code string) (ticket *Ticket, err error) {
+	ticket = &Ticket{}
+	err = r.DB.Where("code = ?", code).First(ticket).Error
+	return
+}
+
+// Scan marks the ticket as used for an entry at the given time. The scan only applies when the
+// ticket wasn't scanned since it was read, so concurrent entries validated on the same read can't
+// both pass, the later ones get ErrTicketScanConflict.
+func (r DAO) Scan(ticket *Ticket, at time.Time) (err error) {
+	result := r.DB.Model(&Ticket{}).
+		Where("id = ? AND scan_count = ?", ticket.ID, ticket.ScanCount).
+		Updates(map[string]interface{}{"scan_count": ticket.ScanCount + 1, "last_scan_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTicketScanConflict
+	}
+	ticket.ScanCount++
+	ticket.LastScanAt = models.TimeP(at)
+	return nil
+}
+
+func newCode() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,58 @@
+package tickets_test
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/drivers/driverstest"
+	"gitlab.com/therako/universal-studios/data/tickets"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+)
+
+func forEachDriver(t *testing.T, test func(t *testing.T, dao tickets.DAO)) {
+	driverstest.ForEach(t, []interface{}{&tickets.Ticket{}}, func(t *testing.T, db *gorm.DB) {
+		test(t, tickets.DAO{DB: db})
+	})
+}
+
+func TestAdd(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, dao tickets.DAO) {
+		ticket := &tickets.Ticket{ValidFrom: time.Now(), ValidTill: time.Now().Add(time.Hour)}
+
+		assert.NilError(t, dao.Add(ticket))
+
+		assert.Assert(t, ticket.Code != "", "expected a code to be generated")
+		assert.Equal(t, tickets.NoReEntry, ticket.ReEntry)
+		stored, err := dao.GetByCode(ticket.Code)
+		assert.NilError(t, err)
+		assert.Equal(t, ticket.ID, stored.ID)
+
+		err = dao.Add(&tickets.Ticket{Code: ticket.Code})
+		assert.Assert(t, err != nil, "expected codes to be unique")
+		err = dao.Add(&tickets.Ticket{ReEntry: "twice"})
+		assert.Equal(t, tickets.ErrUnknownReEntryPolicy, err)
+	})
+}
+
+func TestScan(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, dao tickets.DAO) {
+		ticket := &tickets.Ticket{Code: "abc", ValidFrom: time.Now(), ValidTill: time.Now().Add(time.Hour)}
+		assert.NilError(t, dao.Add(ticket))
+		// Two gates reading the ticket at once
+		gate1, err := dao.GetByCode("abc")
+		assert.NilError(t, err)
+		gate2, err := dao.GetByCode("abc")
+		assert.NilError(t, err)
+
+		at := time.Now().Truncate(time.Second)
+		assert.NilError(t, dao.Scan(gate1, at))
+		assert.Equal(t, tickets.ErrTicketScanConflict, dao.Scan(gate2, at))
+
+		scanned, err := dao.GetByCode("abc")
+		assert.NilError(t, err)
+		assert.Equal(t, uint(1), scanned.ScanCount)
+		assert.Assert(t, scanned.LastScanAt.Equal(at))
+		assert.NilError(t, dao.Scan(scanned, at.Add(time.Minute)), "expected a scan on a fresh read to apply")
+	})
+}
@@ -0,0 +1,38 @@
+// Package domain holds types shared by the data & events packages to describe the studio's rules
+package domain
+
+// Kind groups domain errors by what went wrong, the API maps each kind to a response status
+type Kind int
+
+// Kinds of domain errors
+const (
+	// Invalid input, eg. an unknown queue type
+	Invalid Kind = iota + 1
+	// NotFound when the thing acted on doesn't exist
+	NotFound
+	// Conflict with the current state, eg. a customer who is already in a queue
+	Conflict
+	// Gone when the thing acted on is no longer around, eg. a customer who exited the studio
+	Gone
+	// Forbidden by the studio's rules, eg. a ticket that doesn't allow re-entry
+	Forbidden
+	// Unprocessable when the input is valid but can't be applied, eg. a party larger than the ride
+	Unprocessable
+)
+
+// Error is an expected failure of a studio operation. Code is stable for clients to act on,
+// Message is meant for people.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+// NewError returns a domain error, meant to be declared once as a package var & compared with errors.Is
+func NewError(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -1,117 +1,140 @@
 package customers
 
 import (
-	"errors"
-	"log"
+	"context"
 	"strconv"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
+	"gitlab.com/therako/universal-studios/clock"
 	customersData "gitlab.com/therako/universal-studios/data/customers"
-	"gitlab.com/therako/universal-studios/data/events"
 	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/domain"
 	"gitlab.com/therako/universal-studios/events/rides"
-	"gorm.io/gorm"
+	"gitlab.com/therako/universal-studios/logging"
+	"gitlab.com/therako/universal-studios/metrics"
+	"gitlab.com/therako/universal-studios/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Cache A global cache for customer state
-var Cache *ristretto.Cache
-
 // Errors
 var (
-	ErrCustomerCantBeQueue   = errors.New("Customer is already in a queue or riding")
-	ErrCustomerCantBeUnQueue = errors.New("Customer is not in any queue")
-	ErrCustomerAlreadyExited = errors.New("Customer already exited, but a new entry ticket to queue for rides")
+	ErrCustomerCantBeQueue   = domain.NewError(domain.Conflict, "customer_already_queued", "Customer is already in a queue or riding")
+	ErrCustomerCantBeUnQueue = domain.NewError(domain.Conflict, "customer_not_queued", "Customer is not in any queue")
+	ErrCustomerNotInStudio   = domain.NewError(domain.Gone, "customer_exited", "Customer has exited, re-enter with the ticket to queue for rides")
+	ErrPartyHasNoMembers     = domain.NewError(domain.Invalid, "party_has_no_members", "Party has no members to queue")
 )
 
-func init() {
-	var err error
-	Cache, err = ristretto.NewCache(&ristretto.Config{
-		NumCounters: 1e7,     // number of keys to track frequency of (10M).
-		MaxCost:     1 << 30, // maximum cost of cache (1GB).
-		BufferItems: 64,      // number of keys per Get buffer.
-	})
-	if err != nil {
-		panic(err)
+// Default cache sizing
+const (
+	DefaultCacheNumCounters = 1e7     // 10M keys
+	DefaultCacheMaxCost     = 1 << 30 // 1GB
+)
+
+// NewCache returns a customer state cache of the given size, nil ie. caching off when it isn't sized, the
+// only config ristretto refuses
+func NewCache(numCounters, maxCost int64) *ristretto.Cache {
+	if numCounters <= 0 || maxCost <= 0 {
+		return nil
 	}
+	cache, _ := ristretto.NewCache(&ristretto.Config{
+		NumCounters: numCounters, // number of keys to track frequency of.
+		MaxCost:     maxCost,     // maximum cost of cache.
+		BufferItems: 64,          // number of keys per Get buffer.
+	})
+	return cache
 }
 
 // CustomerState represents a customers current state
 type CustomerState struct {
-	Queueing  bool      `json:"queueing"`
-	RideID    uint      `json:"ride_id"`
-	From      time.Time `json:"from"`
-	To        time.Time `json:"to"`
-	UpdatedAt time.Time `json:"update_at"`
+	Queueing  bool            `json:"queueing"`
+	RideID    uint            `json:"ride_id"`
+	QueueType rides.QueueType `json:"queue_type,omitempty"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	UpdatedAt time.Time       `json:"update_at"`
+
+	// clock the state was aggregated with
+	clock clock.Clock
+}
+
+// now returns the time of the clock the state was aggregated with
+func (s *CustomerState) now() time.Time {
+	return clock.Or(s.clock).Now()
 }
 
 // GetCurrentState from cache or calculate using events from DB
-func GetCurrentState(db *gorm.DB, customer *customersData.Customer) (state *CustomerState, err error) {
-	value, found := Cache.Get(strconv.Itoa(int(customer.ID)))
+func (s *CustomerService) GetCurrentState(ctx context.Context, customer *customersData.Customer) (state *CustomerState, err error) {
+	_, span := tracing.Start(ctx, "customers.Cache.Get", attribute.Int64("customer.id", int64(customer.ID)))
+	value, found := s.Cache.Get(strconv.Itoa(int(customer.ID)))
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+	span.End()
+	metrics.CacheLookup(AggregateRoot, found)
 	if !found {
-		log.Printf("Cache miss for customer %d\n", customer.ID)
-		state, err = aggregateState(db, customer)
+		logging.FromContext(ctx).Debug("Cache miss", "aggregate", AggregateRoot, "id", customer.ID)
+		state, err = s.aggregateState(ctx, customer)
 		return
 	}
 
 	var ok bool
 	if state, ok = value.(*CustomerState); !ok {
-		log.Printf("Cache value for customer %d is invalid\n", customer.ID)
-		state, err = aggregateState(db, customer)
+		logging.FromContext(ctx).Warn("Cache value is invalid", "aggregate", AggregateRoot, "id", customer.ID)
+		state, err = s.aggregateState(ctx, customer)
 	}
 
 	return
 }
 
-// LogCustomerInQueue validates and adds customer to queue of the ride
-func LogCustomerInQueue(db *gorm.DB, customer *customersData.Customer, ride *ridesData.Ride) (err error) {
-	if customer.ExitAt != nil && customer.ExitAt.Before(time.Now()) {
-		return ErrCustomerAlreadyExited
-	}
+// LogCustomerInQueue validates and adds customer to the standby queue of the ride
+func (s *CustomerService) LogCustomerInQueue(ctx context.Context, customer *customersData.Customer, ride *ridesData.Ride) (err error) {
+	return s.LogCustomerInQueueOfType(ctx, customer, ride, rides.Standby)
+}
 
-	var state *CustomerState
-	state, err = GetCurrentState(db, customer)
+// LogCustomerInQueueOfType validates and adds customer to the given queue type of the ride
+func (s *CustomerService) LogCustomerInQueueOfType(ctx context.Context, customer *customersData.Customer, ride *ridesData.Ride, queueType rides.QueueType) (err error) {
+	ctx = logging.With(ctx, "customer_id", customer.ID)
+	err = s.canQueue(ctx, customer)
 	if err != nil {
 		return
 	}
 
-	if state.Queueing && state.To.After(time.Now()) {
-		return ErrCustomerCantBeQueue
-	}
-
-	err = rides.LogCustomerJoinedRideQueue(db, ride, customer)
+	err = s.Rides.LogCustomerJoinedRideQueueOfType(ctx, ride, customer, queueType)
 	if err != nil {
 		return
 	}
 
-	rideState, err := rides.GetCurrentState(db, ride)
+	rideState, err := s.Rides.GetCurrentState(ctx, ride)
 	if err != nil {
 		return
 	}
 
-	now := time.Now()
+	now := s.now()
 	e := &CustomerQueued{
 		Customer: customer,
 		Ride:     ride,
 		From:     now,
 		// To = whole journey (waiting time + ride time)
-		To: rideState.EstimatedWaitTill.Add(ride.RideTime),
+		To:        rideState.Queue(queueType).EstimatedWaitTill.Add(ride.RideTime),
+		QueueType: queueType,
 	}
-	doa := events.DAO{DB: db}
-	err = doa.Add(e)
+	err = s.Events.Add(ctx, e)
 	// State changed - invalidate cache
-	Cache.Del(strconv.Itoa(int(customer.ID)))
+	s.Cache.Del(strconv.Itoa(int(customer.ID)))
+	if err == nil {
+		logging.FromContext(ctx).Info("Customer queued", "ride_id", ride.ID, "queue_type", queueType, "till", e.To)
+	}
 	return
 }
 
 // LogCustomerLeftAQueue validates and removes customer from queue of the ride
-func LogCustomerLeftAQueue(db *gorm.DB, customer *customersData.Customer) (err error) {
-	if customer.ExitAt != nil && customer.ExitAt.Before(time.Now()) {
-		return ErrCustomerAlreadyExited
+func (s *CustomerService) LogCustomerLeftAQueue(ctx context.Context, customer *customersData.Customer) (err error) {
+	ctx = logging.With(ctx, "customer_id", customer.ID)
+	if customer.ExitAt != nil && customer.ExitAt.Before(s.now()) {
+		return ErrCustomerNotInStudio
 	}
 
 	var state *CustomerState
-	state, err = GetCurrentState(db, customer)
+	state, err = s.GetCurrentState(ctx, customer)
 	if err != nil {
 		return
 	}
@@ -120,37 +143,69 @@ func LogCustomerLeftAQueue(db *gorm.DB, customer *customersData.Customer) (err e
 		return ErrCustomerCantBeUnQueue
 	}
 
-	rideDAO := ridesData.DAO{DB: db}
+	rideDAO := ridesData.DAO{DB: s.Events.DB}
 	ride, err := rideDAO.Get(state.RideID)
 	if err != nil {
 		return
 	}
 
-	err = rides.LogCustomerLeftRideQueue(db, ride, customer)
+	return s.unQueue(ctx, customer, ride, state.QueueType)
+}
+
+// unQueue removes the customer from the queue of the ride without any validations
+func (s *CustomerService) unQueue(ctx context.Context, customer *customersData.Customer, ride *ridesData.Ride, queueType rides.QueueType) (err error) {
+	err = s.Rides.LogCustomerLeftRideQueueOfType(ctx, ride, customer, queueType)
 	if err != nil {
 		return
 	}
 
-	now := time.Now()
+	now := s.now()
 	e := &CustomerUnQueued{
 		Customer: customer,
 		At:       now,
 	}
-	doa := events.DAO{DB: db}
-	err = doa.Add(e)
+	err = s.Events.Add(ctx, e)
 	// State changed - invalidate cache
-	Cache.Del(strconv.Itoa(int(customer.ID)))
+	s.Cache.Del(strconv.Itoa(int(customer.ID)))
+	if err == nil {
+		logging.FromContext(ctx).Info("Customer un-queued", "ride_id", ride.ID, "queue_type", queueType)
+	}
 	return
 }
 
-func aggregateState(db *gorm.DB, customer *customersData.Customer) (*CustomerState, error) {
-	newState := &CustomerState{}
+// canQueue validates if the customer is free to join a queue
+func (s *CustomerService) canQueue(ctx context.Context, customer *customersData.Customer) error {
+	if customer.ExitAt != nil && customer.ExitAt.Before(s.now()) {
+		return ErrCustomerNotInStudio
+	}
+
+	state, err := s.GetCurrentState(ctx, customer)
+	if err != nil {
+		return err
+	}
+
+	if state.Queueing && state.To.After(s.now()) {
+		return ErrCustomerCantBeQueue
+	}
+	return nil
+}
+
+// Replay rebuilds the customer's state from it's events, ignoring the cached state
+func (s *CustomerService) Replay(ctx context.Context, customer *customersData.Customer) (state *CustomerState, err error) {
+	return s.aggregateState(ctx, customer)
+}
+
+func (s *CustomerService) aggregateState(ctx context.Context, customer *customersData.Customer) (state *CustomerState, err error) {
+	ctx, span := tracing.Start(ctx, "customers.aggregateState", attribute.Int64("customer.id", int64(customer.ID)))
+	defer func() { tracing.End(span, err) }()
+	newState := &CustomerState{clock: s.Clock}
 
-	dao := events.DAO{DB: db}
-	events, err := dao.EventFor(customer.ID, AggregateRoot)
+	start := time.Now()
+	events, err := s.Events.EventFor(ctx, customer.ID, AggregateRoot)
 	if err != nil {
 		return nil, err
 	}
+	defer metrics.Replayed(AggregateRoot, start, len(events))
 
 	for _, event := range events {
 		switch event.Name {
@@ -169,15 +224,15 @@ func aggregateState(db *gorm.DB, customer *customersData.Customer) (*CustomerSta
 			}
 			e.Aggregate(newState)
 		default:
-			log.Printf("Unknown event received with name: %s\n", event.Name)
+			logging.FromContext(ctx).Warn("Unknown event received", "aggregate", AggregateRoot, "id", customer.ID, "event", event.Name)
 		}
 	}
 
-	newState.UpdatedAt = time.Now()
+	newState.UpdatedAt = newState.now()
 	if newState.Queueing == true {
-		Cache.SetWithTTL(strconv.Itoa(int(customer.ID)), newState, 0, newState.To.Sub(time.Now()))
+		s.Cache.SetWithTTL(strconv.Itoa(int(customer.ID)), newState, 0, newState.To.Sub(newState.UpdatedAt))
 	} else {
-		Cache.Set(strconv.Itoa(int(customer.ID)), newState, 0)
+		s.Cache.Set(strconv.Itoa(int(customer.ID)), newState, 0)
 	}
 
 	return newState, nil
@@ -1,6 +1,8 @@
 package customers_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -8,9 +10,11 @@ import (
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"gitlab.com/therako/universal-studios/clock"
 	customersData "gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/events"
 	"gitlab.com/therako/universal-studios/data/models"
+	partiesData "gitlab.com/therako/universal-studios/data/parties"
 	ridesData "gitlab.com/therako/universal-studios/data/rides"
 	"gitlab.com/therako/universal-studios/events/customers"
 	"gitlab.com/therako/universal-studios/events/rides"
@@ -31,18 +35,21 @@ var (
 	)
 )
 
-func init() {
-	customers.Cache.Clear()
-}
-
 func testDB(name string) *gorm.DB {
 	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{Logger: gormLogger})
 	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
 	gormDB.AutoMigrate(&customersData.Customer{})
+	gormDB.AutoMigrate(&partiesData.Party{})
 	gormDB.AutoMigrate(&ridesData.Ride{})
 	gormDB.AutoMigrate(&events.Event{})
 	return gormDB
 }
+
+// testService returns a service on the DB with caches of it's own, telling the time on the clock
+func testService(db *gorm.DB, clk clock.Clock) *customers.CustomerService {
+	return customers.NewService(db, customers.NewCache(1e3, 1e6), clk, rides.NewService(db, rides.NewCache(1e3, 1e6), clk))
+}
+
 func TestGetCurrentState(t *testing.T) {
 	customer := &customersData.Customer{Model: models.Model{ID: 110}}
 	ride1 := &ridesData.Ride{Name: "ride1"}
@@ -51,30 +58,30 @@ func TestGetCurrentState(t *testing.T) {
 
 	db := testDB(t.Name())
 	dao := events.DAO{DB: db}
-	dao.Add(&customers.CustomerQueued{
+	dao.Add(context.Background(), &customers.CustomerQueued{
 		Customer: customer,
 		Ride:     ride1,
 		From:     customerStartTime,
 		To:       customerStartTime.Add(10 * time.Millisecond),
 	})
-	dao.Add(&customers.CustomerUnQueued{
+	dao.Add(context.Background(), &customers.CustomerUnQueued{
 		Customer: customer,
 	})
-	dao.Add(&customers.CustomerQueued{
+	dao.Add(context.Background(), &customers.CustomerQueued{
 		Customer: customer,
 		Ride:     ride2,
 		From:     customerStartTime.Add(20 * time.Millisecond),
 		To:       customerStartTime.Add(100 * time.Millisecond),
 	})
 
-	state, err := customers.GetCurrentState(db, customer)
+	state, err := customers.GetCurrentState(context.Background(), db, customer)
 	assert.NilError(t, err)
 	assert.Equal(t, ride2.ID, state.RideID)
 	assert.Equal(t, true, state.Queueing)
 	assert.DeepEqual(t, customerStartTime.Add(20*time.Millisecond), state.From)
 	assert.DeepEqual(t, customerStartTime.Add(100*time.Millisecond), state.To)
 
-	stateFromCache, _ := customers.GetCurrentState(db, customer)
+	stateFromCache, _ := customers.GetCurrentState(context.Background(), db, customer)
 	assert.Equal(t, ride2.ID, stateFromCache.RideID)
 	assert.Equal(t, true, stateFromCache.Queueing)
 	assert.DeepEqual(t, customerStartTime.Add(20*time.Millisecond), stateFromCache.From)
@@ -84,7 +91,7 @@ func TestGetCurrentState(t *testing.T) {
 func TestE2ECustomerQueuingFlow(t *testing.T) {
 	db := testDB(t.Name())
 	ts := time.Now()
-	rides.Clock = clockwork.NewFakeClockAt(ts)
+	service := testService(db, clockwork.NewFakeClockAt(ts))
 	customer := &customersData.Customer{Model: models.Model{ID: 111}}
 	db.Create(customer)
 	ride1 := &ridesData.Ride{Model: models.Model{ID: 123}, Name: "ride1", Capacity: 4, RideTime: 10 * time.Minute}
@@ -92,22 +99,22 @@ func TestE2ECustomerQueuingFlow(t *testing.T) {
 	ride2 := &ridesData.Ride{Model: models.Model{ID: 456}, Name: "ride2", Capacity: 4, RideTime: 10 * time.Minute}
 	db.Create(ride2)
 
-	err := customers.LogCustomerInQueue(db, customer, ride1)
+	err := service.LogCustomerInQueue(context.Background(), customer, ride1)
 	assert.NilError(t, err, "expected to queue customer with no error")
 
-	err = customers.LogCustomerInQueue(db, customer, ride2)
+	err = service.LogCustomerInQueue(context.Background(), customer, ride2)
 	assert.Error(t, err, customers.ErrCustomerCantBeQueue.Error(), "expected to fail since customer is already in queue for ride1")
 
-	err = customers.LogCustomerLeftAQueue(db, customer)
+	err = service.LogCustomerLeftAQueue(context.Background(), customer)
 	assert.NilError(t, err, "expected to unqueue customer from ride1")
 
-	err = customers.LogCustomerLeftAQueue(db, customer)
+	err = service.LogCustomerLeftAQueue(context.Background(), customer)
 	assert.Error(t, err, customers.ErrCustomerCantBeUnQueue.Error(), "expected error since customer is already unqueued")
 
-	err = customers.LogCustomerInQueue(db, customer, ride2)
+	err = service.LogCustomerInQueue(context.Background(), customer, ride2)
 	assert.NilError(t, err, "expected to queue customer with no error to ride2")
 
-	state, err := customers.GetCurrentState(db, customer)
+	state, err := service.GetCurrentState(context.Background(), customer)
 	assert.NilError(t, err)
 	assert.Equal(t, ride2.ID, state.RideID)
 	assert.Equal(t, true, state.Queueing)
@@ -115,17 +122,193 @@ func TestE2ECustomerQueuingFlow(t *testing.T) {
 	assert.Assert(t, state.To.After(time.Now()))
 
 	// Fill the ride capacity and validate From & To time in customer state
-	customers.LogCustomerInQueue(db, &customersData.Customer{Model: models.Model{ID: 115}}, ride2)
-	customers.LogCustomerInQueue(db, &customersData.Customer{Model: models.Model{ID: 116}}, ride2)
-	customers.LogCustomerLeftAQueue(db, &customersData.Customer{Model: models.Model{ID: 116}})
-	customers.LogCustomerInQueue(db, &customersData.Customer{Model: models.Model{ID: 117}}, ride2)
-	customers.LogCustomerInQueue(db, &customersData.Customer{Model: models.Model{ID: 118}}, ride2)
+	service.LogCustomerInQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 115}}, ride2)
+	service.LogCustomerInQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 116}}, ride2)
+	service.LogCustomerLeftAQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 116}})
+	service.LogCustomerInQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 117}}, ride2)
+	service.LogCustomerInQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 118}}, ride2)
 
-	state, _ = customers.GetCurrentState(db, &customersData.Customer{Model: models.Model{ID: 117}})
+	state, _ = service.GetCurrentState(context.Background(), &customersData.Customer{Model: models.Model{ID: 117}})
 	// expected to be in the same batch, only time is ride time for this user
 	assert.DeepEqual(t, ts.Add(10*time.Minute), state.To)
 
-	state, _ = customers.GetCurrentState(db, &customersData.Customer{Model: models.Model{ID: 118}})
+	state, _ = service.GetCurrentState(context.Background(), &customersData.Customer{Model: models.Model{ID: 118}})
 	// expected to be in the new batch
 	assert.DeepEqual(t, ts.Add(20*time.Minute), state.To)
 }
+
+func TestRiddenRides(t *testing.T) {
+	customer := &customersData.Customer{Model: models.Model{ID: 140}}
+	ride1 := &ridesData.Ride{Model: models.Model{ID: 1}, Name: "ride1"}
+	ride2 := &ridesData.Ride{Model: models.Model{ID: 2}, Name: "ride2"}
+	ride3 := &ridesData.Ride{Model: models.Model{ID: 3}, Name: "ride3"}
+	ts := time.Now()
+
+	db := testDB(t.Name())
+	dao := events.DAO{DB: db}
+	// Ridden ride1 till the end
+	dao.Add(context.Background(), &customers.CustomerQueued{Customer: customer, Ride: ride1, From: ts.Add(-time.Hour), To: ts.Add(-50 * time.Minute)})
+	// Left the queue for ride2 before riding
+	dao.Add(context.Background(), &customers.CustomerQueued{Customer: customer, Ride: ride2, From: ts.Add(-40 * time.Minute), To: ts.Add(-30 * time.Minute)})
+	dao.Add(context.Background(), &customers.CustomerUnQueued{Customer: customer, At: ts.Add(-35 * time.Minute)})
+	// Still in the queue for ride3
+	dao.Add(context.Background(), &customers.CustomerQueued{Customer: customer, Ride: ride3, From: ts.Add(-time.Minute), To: ts.Add(time.Minute)})
+
+	ridden, err := customers.RiddenRides(context.Background(), db, customer, ts.Add(-2*time.Hour))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[uint]bool{1: true}, ridden)
+
+	ridden, err = customers.RiddenRides(context.Background(), db, customer, ts.Add(-30*time.Minute))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[uint]bool{}, ridden)
+}
+
+func TestRankingStrategies(t *testing.T) {
+	quiet := &customers.Recommendation{
+		Ride:  &ridesData.Ride{Name: "quiet", EstimatedWaitingTime: 20 * time.Minute},
+		State: &rides.RideState{Riders: 1},
+	}
+	popular := &customers.Recommendation{
+		Ride:  &ridesData.Ride{Name: "popular", EstimatedWaitingTime: 5 * time.Minute},
+		State: &rides.RideState{Riders: 100},
+	}
+
+	recommendations := []*customers.Recommendation{quiet, popular}
+	customers.ShortestWait{}.Rank(recommendations)
+	assert.Equal(t, "popular", recommendations[0].Ride.Name)
+
+	customers.LeastRidden{}.Rank(recommendations)
+	assert.Equal(t, "quiet", recommendations[0].Ride.Name)
+}
+
+func TestPartyQueuingFlow(t *testing.T) {
+	db := testDB(t.Name())
+	ts := time.Now()
+	service := testService(db, clockwork.NewFakeClockAt(ts))
+	members := []*customersData.Customer{
+		{Model: models.Model{ID: 150}},
+		{Model: models.Model{ID: 151}},
+		{Model: models.Model{ID: 152}},
+	}
+	db.Create(&members)
+	ride := &ridesData.Ride{Model: models.Model{ID: 789}, Name: "ride1", Capacity: 4, RideTime: 10 * time.Minute}
+	db.Create(ride)
+	party, err := partiesData.DAO{DB: db}.Create("family", []uint{150, 151, 152})
+	assert.NilError(t, err)
+
+	err = service.LogPartyInQueue(context.Background(), party, &ridesData.Ride{Model: models.Model{ID: 790}, Capacity: 2})
+	assert.Assert(t, errors.Is(err, rides.ErrPartyTooLarge), "expected party not to fit in the ride")
+
+	err = service.LogPartyInQueue(context.Background(), party, ride)
+	assert.NilError(t, err, "expected to queue the whole party")
+
+	for _, member := range members {
+		state, err := service.GetCurrentState(context.Background(), member)
+		assert.NilError(t, err)
+		assert.Equal(t, true, state.Queueing)
+		assert.Equal(t, ride.ID, state.RideID)
+	}
+	rideState, err := service.Rides.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(3), rideState.QueueCount)
+	assert.DeepEqual(t, ts, rideState.EstimatedWaitTill)
+
+	// One more customer fills the batch
+	err = service.LogCustomerInQueue(context.Background(), &customersData.Customer{Model: models.Model{ID: 153}}, ride)
+	assert.NilError(t, err)
+	rideState, _ = service.Rides.GetCurrentState(context.Background(), ride)
+	assert.DeepEqual(t, ts.Add(10*time.Minute), rideState.EstimatedWaitTill)
+
+	// A member already queueing fails the whole party
+	service.LogCustomerLeftAQueue(context.Background(), members[0])
+	service.LogCustomerLeftAQueue(context.Background(), members[1])
+	err = service.LogPartyInQueue(context.Background(), party, ride)
+	assert.Assert(t, errors.Is(err, customers.ErrCustomerCantBeQueue))
+	state, _ := service.GetCurrentState(context.Background(), members[0])
+	assert.Equal(t, false, state.Queueing)
+	rideState, _ = service.Rides.GetCurrentState(context.Background(), ride)
+	assert.Equal(t, uint(2), rideState.QueueCount)
+}
+
+func TestRetireRide(t *testing.T) {
+	db := testDB(t.Name())
+	service := testService(db, nil)
+	ride := &ridesData.Ride{Model: models.Model{ID: 791}, Name: "ride1", Capacity: 4, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider"}}
+	other := &ridesData.Ride{Model: models.Model{ID: 792}, Name: "ride2", Capacity: 4, RideTime: 10 * time.Minute}
+	db.Create(ride)
+	db.Create(other)
+	queued := []*customersData.Customer{{Model: models.Model{ID: 160}}, {Model: models.Model{ID: 161}}, {Model: models.Model{ID: 162}}}
+	db.Create(&queued)
+	assert.NilError(t, service.LogCustomerInQueue(context.Background(), queued[0], ride))
+	assert.NilError(t, service.LogCustomerInQueueOfType(context.Background(), queued[1], ride, rides.SingleRider))
+	assert.NilError(t, service.LogCustomerInQueue(context.Background(), queued[2], other))
+
+	unQueued, err := service.RetireRide(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(unQueued))
+	assert.Assert(t, ride.IsRetired())
+	for _, customer := range queued[:2] {
+		state, err := service.GetCurrentState(context.Background(), customer)
+		assert.NilError(t, err)
+		assert.Equal(t, false, state.Queueing)
+	}
+	state, _ := service.GetCurrentState(context.Background(), queued[2])
+	assert.Equal(t, true, state.Queueing, "expected customers of other rides to stay queued")
+	rideState, err := service.Rides.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, true, rideState.Retired)
+	assert.Equal(t, uint(0), rideState.QueueCount)
+	assert.Equal(t, uint(0), rideState.Queue(rides.SingleRider).QueueCount)
+
+	stored, err := ridesData.DAO{DB: db}.Get(ride.ID)
+	assert.NilError(t, err)
+	assert.Assert(t, stored.IsRetired())
+	listed, err := ridesData.DAO{DB: db}.List(ridesData.Filter{})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(listed))
+
+	err = service.LogCustomerInQueue(context.Background(), queued[0], stored)
+	assert.Assert(t, errors.Is(err, rides.ErrRideRetired))
+	_, err = service.RetireRide(context.Background(), stored)
+	assert.Assert(t, errors.Is(err, rides.ErrRideAlreadyRetired))
+	assert.Assert(t, stored.IsRetired())
+
+	assert.NilError(t, service.Rides.LogRideRestored(context.Background(), stored))
+	assert.NilError(t, service.LogCustomerInQueue(context.Background(), queued[0], stored))
+	rideState, _ = service.Rides.GetCurrentState(context.Background(), stored)
+	assert.Equal(t, false, rideState.Retired)
+	assert.Assert(t, errors.Is(service.Rides.LogRideRestored(context.Background(), stored), rides.ErrRideNotRetired))
+}
+
+func TestFlowOnAFakeClock(t *testing.T) {
+	db := testDB(t.Name())
+	at := time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(at)
+	service := testService(db, clock)
+	ride := &ridesData.Ride{Model: models.Model{ID: 793}, Name: "ride1", Capacity: 2, RideTime: 10 * time.Minute}
+	db.Create(ride)
+	riding, leaving := &customersData.Customer{Model: models.Model{ID: 170}}, &customersData.Customer{Model: models.Model{ID: 171}}
+	db.Create(riding)
+	db.Create(leaving)
+
+	assert.NilError(t, service.LogCustomerInQueue(context.Background(), riding, ride))
+	state, err := service.GetCurrentState(context.Background(), riding)
+	assert.NilError(t, err)
+	assert.Assert(t, state.From.Equal(at))
+	assert.Assert(t, state.To.Equal(at.Add(10*time.Minute)))
+
+	// Off the ride once the fake clock passes the ride time, without the real time passing
+	service.Cache.Clear()
+	clock.Advance(11 * time.Minute)
+	state, err = service.Replay(context.Background(), riding)
+	assert.NilError(t, err)
+	assert.Equal(t, false, state.Queueing)
+	assert.Assert(t, state.UpdatedAt.Equal(at.Add(11*time.Minute)))
+
+	exited, err := customersData.DAO{DB: db, Clock: clock}.Exit(leaving.ID)
+	assert.NilError(t, err)
+	assert.Assert(t, exited.ExitAt.Equal(at.Add(11*time.Minute)))
+	clock.Advance(time.Second)
+	err = service.LogCustomerInQueue(context.Background(), exited, ride)
+	assert.Assert(t, errors.Is(err, customers.ErrCustomerNotInStudio))
+}
@@ -7,6 +7,7 @@ import (
 	"gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/events"
 	"gitlab.com/therako/universal-studios/data/rides"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
 )
 
 const (
@@ -16,10 +17,11 @@ const (
 
 // CustomerQueued is an event representing when a customer enters a queue for a ride
 type CustomerQueued struct {
-	Customer *customers.Customer `json:"customer"`
-	Ride     *rides.Ride         `json:"ride"`
-	From     time.Time           `json:"from"`
-	To       time.Time           `json:"to"`
+	Customer  *customers.Customer   `json:"customer"`
+	Ride      *rides.Ride           `json:"ride"`
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	QueueType ridesEvents.QueueType `json:"queue_type,omitempty"`
 }
 
 func (e *CustomerQueued) FromDBEvent(event *events.Event) (err error) {
@@ -44,16 +46,18 @@ func (e CustomerQueued) ToDBEvent() (*events.Event, error) {
 }
 
 func (e CustomerQueued) Aggregate(state *CustomerState) {
-	if e.To.Before(time.Now()) {
+	if e.To.Before(state.now()) {
 		state.Queueing = false
 		state.RideID = 0
-		state.From = time.Now()
+		state.QueueType = ""
+		state.From = state.now()
 		state.To = time.Time{}
 		return
 	}
 
 	state.Queueing = true
 	state.RideID = e.Ride.ID
+	state.QueueType = e.QueueType
 	state.From = e.From
 	state.To = e.To
 }
@@ -87,6 +91,21 @@ func (e CustomerUnQueued) ToDBEvent() (*events.Event, error) {
 func (e CustomerUnQueued) Aggregate(state *CustomerState) {
 	state.Queueing = false
 	state.RideID = 0
+	state.QueueType = ""
 	state.From = e.At
 	state.To = time.Time{}
 }
+
+// ValidateEvent checks the DB event is a known customer event with valid data, eg. before importing it
+func ValidateEvent(event *events.Event) error {
+	var e events.EventInterface
+	switch event.Name {
+	case "CustomerQueued":
+		e = &CustomerQueued{}
+	case "CustomerUnQueued":
+		e = &CustomerUnQueued{}
+	default:
+		return events.ErrUnknownEvent
+	}
+	return e.FromDBEvent(event)
+}
@@ -0,0 +1,67 @@
+package customers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	partiesData "gitlab.com/therako/universal-studios/data/parties"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/logging"
+	"gorm.io/gorm"
+)
+
+// LogPartyInQueue validates and adds all members of the party to queue of the ride.
+// Either the whole party is queued or none of its members are.
+func (s *CustomerService) LogPartyInQueue(ctx context.Context, party *partiesData.Party, ride *ridesData.Ride) (err error) {
+	if len(party.Members) == 0 {
+		return ErrPartyHasNoMembers
+	}
+
+	for _, member := range party.Members {
+		err = s.canQueue(ctx, member)
+		if err != nil {
+			return fmt.Errorf("customer %d: %w", member.ID, err)
+		}
+	}
+
+	defer func() {
+		// State changed or states cached within a rolled back transaction - invalidate cache
+		s.Rides.Invalidate(ride.ID)
+		for _, member := range party.Members {
+			s.Cache.Del(strconv.Itoa(int(member.ID)))
+		}
+	}()
+
+	err = s.Events.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		txs := s.WithDB(tx)
+		err := txs.Rides.LogPartyJoinedRideQueue(ctx, ride, party.Members)
+		if err != nil {
+			return err
+		}
+
+		rideState, err := txs.Rides.GetCurrentState(ctx, ride)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		for _, member := range party.Members {
+			err = txs.Events.Add(ctx, &CustomerQueued{
+				Customer: member,
+				Ride:     ride,
+				From:     now,
+				// To = whole journey (waiting time + ride time)
+				To: rideState.EstimatedWaitTill.Add(ride.RideTime),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		logging.FromContext(ctx).Info("Party queued", "party_id", party.ID, "ride_id", ride.ID, "members", len(party.Members))
+	}
+	return err
+}
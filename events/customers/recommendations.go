@@ -0,0 +1,132 @@
+package customers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	customersData "gitlab.com/therako/universal-studios/data/customers"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/events/rides"
+)
+
+// Recommendation is a ride suggested to a customer along with the ride's current state
+type Recommendation struct {
+	Ride  *ridesData.Ride
+	State *rides.RideState
+}
+
+// RankingStrategy orders recommendations in place, best suggestion first
+type RankingStrategy interface {
+	Rank(recommendations []*Recommendation)
+}
+
+// ShortestWait ranks rides with the least waiting time first
+type ShortestWait struct{}
+
+func (ShortestWait) Rank(recommendations []*Recommendation) {
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Ride.EstimatedWaitingTime < recommendations[j].Ride.EstimatedWaitingTime
+	})
+}
+
+// LeastRidden ranks rides with the fewest riders first, breaking ties on waiting time
+type LeastRidden struct{}
+
+func (LeastRidden) Rank(recommendations []*Recommendation) {
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].State.Riders != recommendations[j].State.Riders {
+			return recommendations[i].State.Riders < recommendations[j].State.Riders
+		}
+		return recommendations[i].Ride.EstimatedWaitingTime < recommendations[j].Ride.EstimatedWaitingTime
+	})
+}
+
+// DefaultStrategy is used when no ranking strategy is asked for
+const DefaultStrategy = "shortest_wait"
+
+// Strategies holds all known ranking strategies by name, new ones can be registered here
+var Strategies = map[string]RankingStrategy{
+	"shortest_wait": ShortestWait{},
+	"least_ridden":  LeastRidden{},
+}
+
+// Recommend returns the rides a customer can go to next ranked by the strategy.
+// Rides that are down or were already ridden by the customer today are excluded.
+func (s *CustomerService) Recommend(ctx context.Context, customer *customersData.Customer, strategy RankingStrategy) ([]*ridesData.Ride, error) {
+	if customer.ExitAt != nil && customer.ExitAt.Before(s.now()) {
+		return nil, ErrCustomerNotInStudio
+	}
+
+	now := s.now()
+	year, month, day := now.Date()
+	ridden, err := s.RiddenRides(ctx, customer, time.Date(year, month, day, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		return nil, err
+	}
+
+	rideDAO := ridesData.DAO{DB: s.Events.DB}
+	allRides, err := rideDAO.List(ridesData.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []*Recommendation{}
+	for _, ride := range allRides {
+		if ridden[ride.ID] {
+			continue
+		}
+
+		rideState, err := s.Rides.GetCurrentState(ctx, ride)
+		if err != nil {
+			return nil, err
+		}
+		if rideState.Down {
+			continue
+		}
+
+		rideState.Apply(ride, now)
+		recommendations = append(recommendations, &Recommendation{Ride: ride, State: rideState})
+	}
+
+	strategy.Rank(recommendations)
+	ranked := make([]*ridesData.Ride, 0, len(recommendations))
+	for _, r := range recommendations {
+		ranked = append(ranked, r.Ride)
+	}
+	return ranked, nil
+}
+
+// RiddenRides returns the rides the customer finished riding since the given time.
+// A queue counts as ridden once its end time passed without the customer leaving it.
+func (s *CustomerService) RiddenRides(ctx context.Context, customer *customersData.Customer, since time.Time) (map[uint]bool, error) {
+	allEvents, err := s.Events.EventFor(ctx, customer.ID, AggregateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	ridden := map[uint]bool{}
+	var pending *CustomerQueued
+	for _, event := range allEvents {
+		switch event.Name {
+		case "CustomerQueued":
+			e := &CustomerQueued{}
+			err = e.FromDBEvent(event)
+			if err != nil {
+				return nil, err
+			}
+			if pending != nil && !pending.To.After(e.From) && !pending.From.Before(since) {
+				ridden[pending.Ride.ID] = true
+			}
+			pending = e
+		case "CustomerUnQueued":
+			pending = nil
+		}
+	}
+
+	if pending != nil && pending.To.Before(s.now()) && !pending.From.Before(since) {
+		ridden[pending.Ride.ID] = true
+	}
+
+	return ridden, nil
+}
@@ -0,0 +1,70 @@
+package customers
+
+import (
+	"context"
+	"strconv"
+
+	customersData "gitlab.com/therako/universal-studios/data/customers"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/logging"
+	"gorm.io/gorm"
+)
+
+// RetireRide takes the ride out of service & un-queues all customers still in it's queues,
+// returning the customers who were un-queued. Either all of it happens or none of it does.
+func (s *CustomerService) RetireRide(ctx context.Context, ride *ridesData.Ride) ([]*customersData.Customer, error) {
+	retiredAt := ride.DeletedAt
+	unQueued := []*customersData.Customer{}
+	defer func() {
+		// State changed or states cached within a rolled back transaction - invalidate cache
+		s.Rides.Invalidate(ride.ID)
+		for _, customer := range unQueued {
+			s.Cache.Del(strconv.Itoa(int(customer.ID)))
+		}
+	}()
+
+	err := s.Events.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		txs := s.WithDB(tx)
+		err := txs.Rides.LogRideRetired(ctx, ride)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		active, err := txs.Events.Active(ctx, AggregateRoot, "CustomerQueued", now)
+		if err != nil {
+			return err
+		}
+		seen := map[uint]bool{}
+		for _, event := range active {
+			if seen[event.SourceID] {
+				continue
+			}
+			seen[event.SourceID] = true
+
+			customer, err := txs.DAO.Get(event.SourceID)
+			if err != nil {
+				return err
+			}
+			state, err := txs.GetCurrentState(ctx, customer)
+			if err != nil {
+				return err
+			}
+			if !state.Queueing || state.RideID != ride.ID || !state.To.After(now) {
+				continue
+			}
+
+			unQueued = append(unQueued, customer)
+			err = txs.unQueue(logging.With(ctx, "customer_id", customer.ID), customer, ride, state.QueueType)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ride.DeletedAt = retiredAt
+		return nil, err
+	}
+	return unQueued, nil
+}
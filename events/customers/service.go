@@ -0,0 +1,116 @@
+package customers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"gorm.io/gorm"
+
+	"gitlab.com/therako/universal-studios/clock"
+	customersData "gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/events"
+	partiesData "gitlab.com/therako/universal-studios/data/parties"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/events/rides"
+)
+
+// CustomerService logs & replays customer events with it's own DAOs, cache & clock, queueing on the
+// rides through it's ride service which holds the event bus. The package functions are thin
+// wrappers on NewService.
+type CustomerService struct {
+	DAO    customersData.DAO
+	Events events.DAO
+	// Cache of customer states, nil turns caching off
+	Cache *ristretto.Cache
+	// Clock the real clock when nil, expected to be the ride service's clock
+	Clock clock.Clock
+	Rides *rides.RideService
+}
+
+// NewService returns a service on the DB with the given cache & clock, queueing through the ride
+// service which is expected to be on the same clock
+func NewService(db *gorm.DB, cache *ristretto.Cache, clk clock.Clock, rideService *rides.RideService) *CustomerService {
+	return &CustomerService{
+		DAO:    customersData.DAO{DB: db, Clock: clk},
+		Events: events.DAO{DB: db},
+		Cache:  cache,
+		Clock:  clk,
+		Rides:  rideService,
+	}
+}
+
+// The package functions' cache, made on first use
+var (
+	defaults     sync.Once
+	defaultCache *ristretto.Cache
+)
+
+// DefaultService returns a service on the DB with the package's cache & the rides package's default
+// service, the one the package functions are thin wrappers on
+func DefaultService(db *gorm.DB) *CustomerService {
+	defaults.Do(func() {
+		defaultCache = NewCache(DefaultCacheNumCounters, DefaultCacheMaxCost)
+	})
+	rideService := rides.DefaultService(db)
+	return NewService(db, defaultCache, rideService.Clock, rideService)
+}
+
+// WithDB returns a copy of the service & it's ride service on the DB, eg. a transaction
+func (s *CustomerService) WithDB(db *gorm.DB) *CustomerService {
+	withDB := *s
+	withDB.DAO.DB = db
+	withDB.Events = events.DAO{DB: db}
+	withDB.Rides = s.Rides.WithDB(db)
+	return &withDB
+}
+
+func (s *CustomerService) now() time.Time {
+	return clock.Or(s.Clock).Now()
+}
+
+// GetCurrentState from cache or calculate using events from DB
+func GetCurrentState(ctx context.Context, db *gorm.DB, customer *customersData.Customer) (*CustomerState, error) {
+	return DefaultService(db).GetCurrentState(ctx, customer)
+}
+
+// LogCustomerInQueue validates and adds customer to the standby queue of the ride
+func LogCustomerInQueue(ctx context.Context, db *gorm.DB, customer *customersData.Customer, ride *ridesData.Ride) error {
+	return DefaultService(db).LogCustomerInQueue(ctx, customer, ride)
+}
+
+// LogCustomerInQueueOfType validates and adds customer to the given queue type of the ride
+func LogCustomerInQueueOfType(ctx context.Context, db *gorm.DB, customer *customersData.Customer, ride *ridesData.Ride, queueType rides.QueueType) error {
+	return DefaultService(db).LogCustomerInQueueOfType(ctx, customer, ride, queueType)
+}
+
+// LogCustomerLeftAQueue validates and removes customer from queue of the ride
+func LogCustomerLeftAQueue(ctx context.Context, db *gorm.DB, customer *customersData.Customer) error {
+	return DefaultService(db).LogCustomerLeftAQueue(ctx, customer)
+}
+
+// LogPartyInQueue validates and adds all members of the party to queue of the ride, all or none of them
+func LogPartyInQueue(ctx context.Context, db *gorm.DB, party *partiesData.Party, ride *ridesData.Ride) error {
+	return DefaultService(db).LogPartyInQueue(ctx, party, ride)
+}
+
+// RetireRide takes the ride out of service & un-queues all customers still in it's queues
+func RetireRide(ctx context.Context, db *gorm.DB, ride *ridesData.Ride) ([]*customersData.Customer, error) {
+	return DefaultService(db).RetireRide(ctx, ride)
+}
+
+// Recommend returns the rides a customer can go to next ranked by the strategy
+func Recommend(ctx context.Context, db *gorm.DB, customer *customersData.Customer, strategy RankingStrategy) ([]*ridesData.Ride, error) {
+	return DefaultService(db).Recommend(ctx, customer, strategy)
+}
+
+// RiddenRides returns the rides the customer finished riding since the given time
+func RiddenRides(ctx context.Context, db *gorm.DB, customer *customersData.Customer, since time.Time) (map[uint]bool, error) {
+	return DefaultService(db).RiddenRides(ctx, customer, since)
+}
+
+// Replay rebuilds the customer's state from it's events, ignoring the cached state
+func Replay(ctx context.Context, db *gorm.DB, customer *customersData.Customer) (*CustomerState, error) {
+	return DefaultService(db).Replay(ctx, customer)
+}
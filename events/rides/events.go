@@ -20,6 +20,9 @@ type RideCustomerQueued struct {
 	Customer *customers.Customer `json:"customer"`
 	From     time.Time           `json:"From"`
 	To       time.Time           `json:"To"`
+	// PartySize is set when a whole party queues together, Customer is then the first member
+	PartySize uint      `json:"party_size,omitempty"`
+	QueueType QueueType `json:"queue_type,omitempty"`
 }
 
 func (e *RideCustomerQueued) FromDBEvent(event *events.Event) (err error) {
@@ -44,20 +47,33 @@ func (e RideCustomerQueued) ToDBEvent() (*events.Event, error) {
 }
 
 func (e RideCustomerQueued) Aggregate(state *RideState) {
-	if e.To.Before(time.Now()) {
+	size := e.PartySize
+	if size == 0 {
+		size = 1
+	}
+
+	state.Riders += size
+	if e.To.Before(state.now()) {
 		// Skip ended events
 		return
 	}
 
-	state.QueueCount++
-	state.calculateNewWait(e.Ride, false)
+	queue := state.queue(e.QueueType)
+	// Seat party members one by one, since they can spill over to the next batch
+	for i := uint(0); i < size; i++ {
+		queue.QueueCount++
+		if !e.QueueType.isEstimatedAfterReplay() {
+			queue.calculateNewWait(e.Ride, false, state.now())
+		}
+	}
 }
 
 // RideCustomerUnQueued is an event representing rides when customers leaves the queue
 type RideCustomerUnQueued struct {
-	Ride     *ridesData.Ride     `json:"ride"`
-	Customer *customers.Customer `json:"customer"`
-	At       time.Time           `json:"At"`
+	Ride      *ridesData.Ride     `json:"ride"`
+	Customer  *customers.Customer `json:"customer"`
+	At        time.Time           `json:"At"`
+	QueueType QueueType           `json:"queue_type,omitempty"`
 }
 
 func (e *RideCustomerUnQueued) FromDBEvent(event *events.Event) (err error) {
@@ -81,10 +97,127 @@ func (e RideCustomerUnQueued) ToDBEvent() (*events.Event, error) {
 }
 
 func (e RideCustomerUnQueued) Aggregate(state *RideState) {
-	if state.QueueCount == 0 {
+	if state.Riders > 0 {
+		state.Riders--
+	}
+	queue := state.queue(e.QueueType)
+	if queue.QueueCount == 0 {
 		return
 	}
 
-	state.QueueCount--
-	state.calculateNewWait(e.Ride, true)
+	queue.QueueCount--
+	if !e.QueueType.isEstimatedAfterReplay() {
+		queue.calculateNewWait(e.Ride, true, state.now())
+	}
+}
+
+// RideStatusChanged is an event representing a ride going down for maintenance or coming back up
+type RideStatusChanged struct {
+	Ride *ridesData.Ride `json:"ride"`
+	Down bool            `json:"down"`
+	At   time.Time       `json:"At"`
+}
+
+func (e *RideStatusChanged) FromDBEvent(event *events.Event) (err error) {
+	err = json.Unmarshal(event.Data, e)
+	return
+}
+
+func (e RideStatusChanged) ToDBEvent() (*events.Event, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events.Event{
+		SourceID:      e.Ride.ID,
+		AggregateRoot: AggregateRoot,
+		Name:          "RideStatusChanged",
+		At:            e.At,
+		Data:          data,
+	}, nil
+}
+
+func (e RideStatusChanged) Aggregate(state *RideState) {
+	state.Down = e.Down
+}
+
+// RideRetired is an event representing a ride taken out of service, it's soft deleted
+type RideRetired struct {
+	Ride *ridesData.Ride `json:"ride"`
+	At   time.Time       `json:"at"`
+}
+
+func (e *RideRetired) FromDBEvent(event *events.Event) (err error) {
+	err = json.Unmarshal(event.Data, e)
+	return
+}
+
+func (e RideRetired) ToDBEvent() (*events.Event, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events.Event{
+		SourceID:      e.Ride.ID,
+		AggregateRoot: AggregateRoot,
+		Name:          "RideRetired",
+		At:            e.At,
+		Data:          data,
+	}, nil
+}
+
+func (e RideRetired) Aggregate(state *RideState) {
+	state.Retired = true
+}
+
+// RideRestored is an event representing a retired ride brought back into service
+type RideRestored struct {
+	Ride *ridesData.Ride `json:"ride"`
+	At   time.Time       `json:"at"`
+}
+
+func (e *RideRestored) FromDBEvent(event *events.Event) (err error) {
+	err = json.Unmarshal(event.Data, e)
+	return
+}
+
+func (e RideRestored) ToDBEvent() (*events.Event, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events.Event{
+		SourceID:      e.Ride.ID,
+		AggregateRoot: AggregateRoot,
+		Name:          "RideRestored",
+		At:            e.At,
+		Data:          data,
+	}, nil
+}
+
+func (e RideRestored) Aggregate(state *RideState) {
+	state.Retired = false
+}
+
+// ValidateEvent checks the DB event is a known ride event with valid data, eg. before importing it
+func ValidateEvent(event *events.Event) error {
+	var e events.EventInterface
+	switch event.Name {
+	case "RideCustomerQueued":
+		e = &RideCustomerQueued{}
+	case "RideCustomerUnQueued":
+		e = &RideCustomerUnQueued{}
+	case "RideStatusChanged":
+		e = &RideStatusChanged{}
+	case "RideRetired":
+		e = &RideRetired{}
+	case "RideRestored":
+		e = &RideRestored{}
+	default:
+		return events.ErrUnknownEvent
+	}
+	return e.FromDBEvent(event)
 }
@@ -0,0 +1,63 @@
+package rides
+
+import (
+	"context"
+	"sort"
+
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+)
+
+// List returns rides matching the filter with their current waiting times applied.
+// Filters & sorts on the waiting time are done here as they need the ride states.
+func (s *RideService) List(ctx context.Context, filter ridesData.Filter) ([]*ridesData.Ride, error) {
+	dao := ridesData.DAO{DB: s.DAO.DB}
+	allRides, err := dao.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*ridesData.Ride, 0, len(allRides))
+	for _, ride := range allRides {
+		state, err := s.GetCurrentState(ctx, ride)
+		if err != nil {
+			return nil, err
+		}
+		state.Apply(ride, s.now())
+		if filter.MaxWait != nil && ride.EstimatedWaitingTime > *filter.MaxWait {
+			continue
+		}
+		filtered = append(filtered, ride)
+	}
+
+	if filter.Sort == ridesData.SortByWait {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filter.Desc {
+				return filtered[i].EstimatedWaitingTime > filtered[j].EstimatedWaitingTime
+			}
+			return filtered[i].EstimatedWaitingTime < filtered[j].EstimatedWaitingTime
+		})
+	}
+	return filtered, nil
+}
+
+// Page returns pageSize rides matching the filter from offset, all of them when pageSize is 0. The
+// rides are filtered & sorted as a whole before the page is cut, so waits are sorted across pages.
+// next is the offset of the following page, 0 on the last one.
+func (s *RideService) Page(ctx context.Context, filter ridesData.Filter, offset int, pageSize int) (page []*ridesData.Ride, next int, err error) {
+	if pageSize < 0 || pageSize > ridesData.MaxPageSize {
+		return nil, 0, ridesData.ErrInvalidPageSize
+	}
+	filtered, err := s.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset > len(filtered) {
+		offset = len(filtered)
+	}
+	filtered = filtered[offset:]
+	if pageSize == 0 || len(filtered) <= pageSize {
+		return filtered, 0, nil
+	}
+	return filtered[:pageSize], offset + pageSize, nil
+}
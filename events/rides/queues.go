@@ -0,0 +1,219 @@
+package rides
+
+import (
+	"fmt"
+	"time"
+
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+)
+
+// QueueType names the different lines a ride can be queued through
+type QueueType string
+
+// Queue types, every ride has a standby queue the rest are enabled per ride
+const (
+	Standby     QueueType = "standby"
+	SingleRider QueueType = "single_rider"
+	Virtual     QueueType = "virtual"
+	// Priority is for accessibility & express pass holders merging into standby at boarding
+	Priority QueueType = "priority"
+)
+
+// isEstimatedAfterReplay is true for queues merging into standby batches,
+// their waits depend on all the other queues so are estimated after all events are played
+func (t QueueType) isEstimatedAfterReplay() bool {
+	return t == SingleRider || t == Priority
+}
+
+// ValidateQueueType checks if the ride can be queued through the queue type
+func ValidateQueueType(ride *ridesData.Ride, queueType QueueType) error {
+	switch queueType {
+	case "", Standby:
+		return nil
+	case SingleRider, Virtual, Priority:
+		if !ride.QueueTypes.Contains(string(queueType)) {
+			return ErrQueueTypeNotSupported
+		}
+		return nil
+	default:
+		return ErrUnknownQueueType
+	}
+}
+
+// ValidateQueueTypes checks the queue types a ride is configured with are all known
+func ValidateQueueTypes(queueTypes []string) error {
+	for _, queueType := range queueTypes {
+		switch QueueType(queueType) {
+		case Standby, SingleRider, Virtual, Priority:
+		default:
+			return fmt.Errorf("%w %s", ErrUnknownQueueType, queueType)
+		}
+	}
+	return nil
+}
+
+// QueueState represents the state of a single queue of a ride
+type QueueState struct {
+	QueueCount        uint      `json:"queue_count"`
+	EstimatedWaitTill time.Time `json:"estimated_wait_till"`
+}
+
+// WaitTime returns how long a customer joining the queue at now would wait
+func (s *QueueState) WaitTime(now time.Time) time.Duration {
+	if s.EstimatedWaitTill.IsZero() || s.EstimatedWaitTill.Before(now) {
+		return 0
+	}
+	return s.EstimatedWaitTill.Sub(now)
+}
+
+func (s *QueueState) calculateNewWait(ride *ridesData.Ride, isReduced bool, now time.Time) {
+	if s.EstimatedWaitTill.IsZero() {
+		s.EstimatedWaitTill = now
+	}
+
+	batches := (s.QueueCount / ride.Capacity)
+	if batches == 0 {
+		s.EstimatedWaitTill = now
+		return
+	}
+
+	remainingSeatsInCurrentBatch := (s.QueueCount % ride.Capacity)
+	if !isReduced && remainingSeatsInCurrentBatch == 0 && batches >= 1 {
+		// Filled capacity by another batch in the queue, add ride time for the estimated_wait
+		s.EstimatedWaitTill = s.EstimatedWaitTill.Add(ride.RideTime)
+		return
+	}
+
+	if isReduced && remainingSeatsInCurrentBatch == ride.Capacity-1 {
+		// A seat got vacated in the previous batch due to some one leaving the queue
+		s.EstimatedWaitTill = s.EstimatedWaitTill.Add(-ride.RideTime)
+		return
+	}
+
+	if s.EstimatedWaitTill.Before(now) {
+		// Wait time can't be in the past, so adjsut wait to now
+		s.EstimatedWaitTill = now
+	}
+	return
+}
+
+// mergeRatio returns how many priority customers board in each batch at the merge point.
+// Atleast one seat in every batch is left for standby, unless the ride seats only one.
+func mergeRatio(ride *ridesData.Ride) uint {
+	ratio := ride.PriorityMergeRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	if ratio >= ride.Capacity && ride.Capacity > 1 {
+		ratio = ride.Capacity - 1
+	}
+	return ratio
+}
+
+// estimateMergedWaits plays the queues merging at the boarding point batch by batch.
+// Priority customers board first upto the merge ratio, standby fill the rest of the batch &
+// single riders fill the seats left over. So priority customers push back standby,
+// but single riders never push back any one.
+func (s *RideState) estimateMergedWaits(ride *ridesData.Ride) {
+	priority, hasPriority := s.Queues[Priority]
+	singleRiders, hasSingleRiders := s.Queues[SingleRider]
+	if !hasPriority && !hasSingleRiders {
+		return
+	}
+
+	ratio := mergeRatio(ride)
+	remainingPriority := uint(0)
+	if hasPriority {
+		remainingPriority = priority.QueueCount
+	}
+	remainingStandby := s.QueueCount
+	remainingSingleRiders := uint(0)
+	if hasSingleRiders {
+		remainingSingleRiders = singleRiders.QueueCount
+	}
+
+	// Batch in which the next customer joining each queue would board
+	priorityBatch, standbyBatch, singleRiderBatch := -1, -1, -1
+	for batch := 0; priorityBatch < 0 || standbyBatch < 0 || singleRiderBatch < 0; batch++ {
+		seats := ride.Capacity
+
+		boarding := min(ratio, remainingPriority)
+		seats -= boarding
+		remainingPriority -= boarding
+		if priorityBatch < 0 && boarding < ratio {
+			priorityBatch = batch
+		}
+
+		boarding = min(seats, remainingStandby)
+		seats -= boarding
+		remainingStandby -= boarding
+		if standbyBatch < 0 && seats > 0 {
+			standbyBatch = batch
+		}
+
+		boarding = min(seats, remainingSingleRiders)
+		seats -= boarding
+		remainingSingleRiders -= boarding
+		if singleRiderBatch < 0 && seats > 0 {
+			singleRiderBatch = batch
+		}
+	}
+
+	now := s.now()
+	standbyBoarding := s.EstimatedWaitTill
+	if standbyBoarding.Before(now) {
+		standbyBoarding = now
+	}
+	// Standby estimate so far assumed all seats are theirs, push it back by the batches priority took
+	if delay := standbyBatch - int(s.QueueCount/ride.Capacity); delay > 0 {
+		standbyBoarding = standbyBoarding.Add(time.Duration(delay) * ride.RideTime)
+		s.EstimatedWaitTill = standbyBoarding
+	}
+
+	if hasPriority {
+		priority.EstimatedWaitTill = now.Add(time.Duration(priorityBatch) * ride.RideTime)
+	}
+	if hasSingleRiders {
+		singleRiders.EstimatedWaitTill = standbyBoarding.Add(time.Duration(singleRiderBatch-standbyBatch) * ride.RideTime)
+	}
+}
+
+func min(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// nextWaitTill returns the earliest wait across all queues which is still in the future
+func (s *RideState) nextWaitTill() time.Time {
+	now := s.now()
+	next := s.EstimatedWaitTill
+	for _, q := range s.Queues {
+		if q.EstimatedWaitTill.After(now) && (!next.After(now) || q.EstimatedWaitTill.Before(next)) {
+			next = q.EstimatedWaitTill
+		}
+	}
+	return next
+}
+
+// Apply sets the calculated waiting times & queue counts of all queues on the ride, reading copies of
+// the queues so a cached state is never changed
+func (s *RideState) Apply(ride *ridesData.Ride, now time.Time) {
+	ride.EstimatedWaitingTime = s.WaitTime(now)
+	ride.InQueue = s.QueueCount
+	for _, queueType := range ride.QueueTypes {
+		if ride.QueueWaitingTimes == nil {
+			ride.QueueWaitingTimes = map[string]time.Duration{}
+			ride.QueueInCounts = map[string]uint{}
+		}
+		queue := s.Queue(QueueType(queueType))
+		if QueueType(queueType) == SingleRider && queue.EstimatedWaitTill.IsZero() {
+			// No single riders seen yet, they'd board with the next standby batch
+			ride.QueueWaitingTimes[queueType] = s.WaitTime(now)
+		} else {
+			ride.QueueWaitingTimes[queueType] = queue.WaitTime(now)
+		}
+		ride.QueueInCounts[queueType] = queue.QueueCount
+	}
+}
@@ -1,135 +1,283 @@
 package rides
 
 import (
-	"log"
+	"context"
 	"strconv"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
 	"gorm.io/gorm"
 
-	clock "github.com/jonboulle/clockwork"
-
+	"gitlab.com/therako/universal-studios/clock"
 	"gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/events"
+	"gitlab.com/therako/universal-studios/data/models"
 	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/domain"
+	"gitlab.com/therako/universal-studios/logging"
+	"gitlab.com/therako/universal-studios/metrics"
+	"gitlab.com/therako/universal-studios/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Cache A global cache for customer state
-var Cache *ristretto.Cache
+// Errors
+var (
+	ErrPartyTooLarge         = domain.NewError(domain.Unprocessable, "party_too_large", "Party is larger than the ride's capacity")
+	ErrUnknownQueueType      = domain.NewError(domain.Invalid, "unknown_queue_type", "Unknown queue type")
+	ErrQueueTypeNotSupported = domain.NewError(domain.Unprocessable, "queue_type_not_supported", "Ride doesn't support this queue type")
+	ErrRideRetired           = domain.NewError(domain.Gone, "ride_retired", "Ride is retired")
+	ErrRideAlreadyRetired    = domain.NewError(domain.Conflict, "ride_already_retired", "Ride is already retired")
+	ErrRideNotRetired        = domain.NewError(domain.Conflict, "ride_not_retired", "Ride is not retired")
+)
 
-// Clock - for test overrides only
-var Clock clock.Clock
+// Default cache sizing
+const (
+	DefaultCacheNumCounters = 1e7     // 10M keys
+	DefaultCacheMaxCost     = 1 << 30 // 1GB
+)
 
-func init() {
-	Clock = clock.NewRealClock()
-	var err error
-	Cache, err = ristretto.NewCache(&ristretto.Config{
-		NumCounters: 1e7,     // number of keys to track frequency of (10M).
-		MaxCost:     1 << 30, // maximum cost of cache (1GB).
-		BufferItems: 64,      // number of keys per Get buffer.
-	})
-	if err != nil {
-		panic(err)
+// NewCache returns a ride state cache of the given size, nil ie. caching off when it isn't sized, the
+// only config ristretto refuses
+func NewCache(numCounters, maxCost int64) *ristretto.Cache {
+	if numCounters <= 0 || maxCost <= 0 {
+		return nil
 	}
+	cache, _ := ristretto.NewCache(&ristretto.Config{
+		NumCounters: numCounters, // number of keys to track frequency of.
+		MaxCost:     maxCost,     // maximum cost of cache.
+		BufferItems: 64,          // number of keys per Get buffer.
+	})
+	return cache
 }
 
 // RideState represents a ride's current state
 type RideState struct {
-	UpdatedAt         time.Time `json:"update_at"`
-	QueueCount        uint      `json:"queue_count"`
-	EstimatedWaitTill time.Time `json:"estimated_wait_till"`
+	UpdatedAt time.Time `json:"update_at"`
+	// Standby queue of the ride
+	QueueState
+	// Queues holds the state of all other queue types of the ride, eg. single rider
+	Queues map[QueueType]*QueueState `json:"queues,omitempty"`
+	// Riders counts every customer who queued for the ride and didn't leave the queue
+	Riders uint `json:"riders"`
+	Down   bool `json:"down"`
+	// Retired rides can't be queued for till they're restored
+	Retired bool `json:"retired,omitempty"`
+
+	// clock the state was aggregated with
+	clock clock.Clock
 }
 
-func (s *RideState) calculateNewWait(ride *ridesData.Ride, isReduced bool) {
-	if s.EstimatedWaitTill.IsZero() {
-		s.EstimatedWaitTill = Clock.Now()
-	}
+// now returns the time of the clock the state was aggregated with
+func (s *RideState) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
 
-	batches := (s.QueueCount / ride.Capacity)
-	if batches == 0 {
-		s.EstimatedWaitTill = Clock.Now()
-		return
+// Queue returns a copy of the state of a queue type, an empty one if it's not seen yet. States may
+// be shared through the cache, so it never changes the state.
+func (s *RideState) Queue(queueType QueueType) QueueState {
+	if queueType == "" || queueType == Standby {
+		return s.QueueState
 	}
-
-	remainingSeatsInCurrentBatch := (s.QueueCount % ride.Capacity)
-	if !isReduced && remainingSeatsInCurrentBatch == 0 && batches >= 1 {
-		// Filled capacity by another batch in the queue, add ride time for the estimated_wait
-		s.EstimatedWaitTill = s.EstimatedWaitTill.Add(ride.RideTime)
-		return
+	if queue, ok := s.Queues[queueType]; ok {
+		return *queue
 	}
+	return QueueState{}
+}
 
-	if isReduced && remainingSeatsInCurrentBatch == ride.Capacity-1 {
-		// A seat got vacated in the previous batch due to some one leaving the queue
-		s.EstimatedWaitTill = s.EstimatedWaitTill.Add(-ride.RideTime)
-		return
+// queue returns the state of a queue type to aggregate events on, creating one if it's not seen yet.
+// Only to be used on states being aggregated, before they're cached.
+func (s *RideState) queue(queueType QueueType) *QueueState {
+	if queueType == "" || queueType == Standby {
+		return &s.QueueState
 	}
 
-	if s.EstimatedWaitTill.Before(Clock.Now()) {
-		// Wait time can't be in the past, so adjsut wait to now
-		s.EstimatedWaitTill = Clock.Now()
+	if s.Queues == nil {
+		s.Queues = map[QueueType]*QueueState{}
 	}
-	return
+	if _, ok := s.Queues[queueType]; !ok {
+		s.Queues[queueType] = &QueueState{}
+	}
+	return s.Queues[queueType]
 }
 
 // GetCurrentState from cache or calculate using events from DB
-func GetCurrentState(db *gorm.DB, ride *ridesData.Ride) (state *RideState, err error) {
-	value, found := Cache.Get(strconv.Itoa(int(ride.ID)))
+func (s *RideService) GetCurrentState(ctx context.Context, ride *ridesData.Ride) (state *RideState, err error) {
+	_, span := tracing.Start(ctx, "rides.Cache.Get", attribute.Int64("ride.id", int64(ride.ID)))
+	value, found := s.Cache.Get(strconv.Itoa(int(ride.ID)))
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+	span.End()
+	metrics.CacheLookup(AggregateRoot, found)
 	if !found {
-		log.Printf("Cache miss for ride %d\n", ride.ID)
-		state, err = aggregateState(db, ride)
+		logging.FromContext(ctx).Debug("Cache miss", "aggregate", AggregateRoot, "id", ride.ID)
+		state, err = s.aggregateState(ctx, ride)
 		return
 	}
 
 	var ok bool
 	if state, ok = value.(*RideState); !ok {
-		log.Printf("Cache value for ride %d is invalid\n", ride.ID)
-		state, err = aggregateState(db, ride)
+		logging.FromContext(ctx).Warn("Cache value is invalid", "aggregate", AggregateRoot, "id", ride.ID)
+		state, err = s.aggregateState(ctx, ride)
 	}
 
 	return
 }
 
-// LogCustomerJoinedRideQueue validates and adds customer in queue of the ride
-func LogCustomerJoinedRideQueue(db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer) (err error) {
-	now := time.Now()
+// LogCustomerJoinedRideQueue validates and adds customer in the standby queue of the ride
+func (s *RideService) LogCustomerJoinedRideQueue(ctx context.Context, ride *ridesData.Ride, customer *customers.Customer) (err error) {
+	return s.LogCustomerJoinedRideQueueOfType(ctx, ride, customer, Standby)
+}
+
+// LogCustomerJoinedRideQueueOfType validates and adds customer in the given queue type of the ride
+func (s *RideService) LogCustomerJoinedRideQueueOfType(ctx context.Context, ride *ridesData.Ride, customer *customers.Customer, queueType QueueType) (err error) {
+	if ride.IsRetired() {
+		return ErrRideRetired
+	}
+	err = ValidateQueueType(ride, queueType)
+	if err != nil {
+		return
+	}
+
+	now := s.now()
 	e := &RideCustomerQueued{
 		Ride:     ride,
 		Customer: customer,
 		From:     now,
 		// Fix -- Add ride waiting time estimates here
-		To: now.Add(ride.RideTime),
+		To:        now.Add(ride.RideTime),
+		QueueType: queueType,
 	}
-	doa := events.DAO{DB: db}
-	err = doa.Add(e)
+	err = s.DAO.Add(ctx, e)
 	// State changed - invalidate cache
-	Cache.Del(strconv.Itoa(int(ride.ID)))
+	s.Invalidate(ride.ID)
 	return
 }
 
-// LogCustomerLeftRideQueue validates and removes customer from queue of the ride
-func LogCustomerLeftRideQueue(db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer) (err error) {
-	now := time.Now()
+// LogPartyJoinedRideQueue adds all members of a party in queue of the ride as a single event
+func (s *RideService) LogPartyJoinedRideQueue(ctx context.Context, ride *ridesData.Ride, members []*customers.Customer) (err error) {
+	if len(members) == 0 {
+		return nil
+	}
+	if ride.IsRetired() {
+		return ErrRideRetired
+	}
+	if uint(len(members)) > ride.Capacity {
+		return ErrPartyTooLarge
+	}
+
+	now := s.now()
+	e := &RideCustomerQueued{
+		Ride:      ride,
+		Customer:  members[0],
+		From:      now,
+		To:        now.Add(ride.RideTime),
+		PartySize: uint(len(members)),
+	}
+	err = s.DAO.Add(ctx, e)
+	// State changed - invalidate cache
+	s.Invalidate(ride.ID)
+	return
+}
+
+// LogCustomerLeftRideQueue validates and removes customer from the standby queue of the ride
+func (s *RideService) LogCustomerLeftRideQueue(ctx context.Context, ride *ridesData.Ride, customer *customers.Customer) (err error) {
+	return s.LogCustomerLeftRideQueueOfType(ctx, ride, customer, Standby)
+}
+
+// LogCustomerLeftRideQueueOfType validates and removes customer from the given queue type of the ride
+func (s *RideService) LogCustomerLeftRideQueueOfType(ctx context.Context, ride *ridesData.Ride, customer *customers.Customer, queueType QueueType) (err error) {
+	now := s.now()
 	e := &RideCustomerUnQueued{
-		Ride:     ride,
-		Customer: customer,
-		At:       now,
+		Ride:      ride,
+		Customer:  customer,
+		At:        now,
+		QueueType: queueType,
+	}
+	err = s.DAO.Add(ctx, e)
+	// State changed - invalidate cache
+	s.Invalidate(ride.ID)
+	return
+}
+
+// LogRideStatus marks the ride as down or back up
+func (s *RideService) LogRideStatus(ctx context.Context, ride *ridesData.Ride, down bool) (err error) {
+	e := &RideStatusChanged{
+		Ride: ride,
+		Down: down,
+		At:   s.now(),
 	}
-	doa := events.DAO{DB: db}
-	err = doa.Add(e)
+	err = s.DAO.Add(ctx, e)
 	// State changed - invalidate cache
-	Cache.Del(strconv.Itoa(int(ride.ID)))
+	s.Invalidate(ride.ID)
+	if err == nil {
+		logging.FromContext(ctx).Info("Ride status changed", "ride_id", ride.ID, "down", down)
+	}
+	return
+}
+
+// LogRideRetired soft deletes the ride taking it out of service, customers still in it's queues
+// are to be un-queued by the caller
+func (s *RideService) LogRideRetired(ctx context.Context, ride *ridesData.Ride) (err error) {
+	if ride.IsRetired() {
+		return ErrRideAlreadyRetired
+	}
+
+	now := s.now()
+	err = s.DAO.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		err := ridesData.DAO{DB: tx}.Retire(ride, now)
+		if err != nil {
+			return err
+		}
+		return events.DAO{DB: tx}.Add(ctx, &RideRetired{Ride: ride, At: now})
+	})
+	if err != nil {
+		return
+	}
+	ride.DeletedAt = models.TimeP(now)
+	// State changed - invalidate cache
+	s.Invalidate(ride.ID)
+	logging.FromContext(ctx).Info("Ride retired", "ride_id", ride.ID)
+	return
+}
+
+// LogRideRestored brings a retired ride back into service
+func (s *RideService) LogRideRestored(ctx context.Context, ride *ridesData.Ride) (err error) {
+	if !ride.IsRetired() {
+		return ErrRideNotRetired
+	}
+
+	err = s.DAO.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		err := ridesData.DAO{DB: tx}.Restore(ride)
+		if err != nil {
+			return err
+		}
+		return events.DAO{DB: tx}.Add(ctx, &RideRestored{Ride: ride, At: s.now()})
+	})
+	if err != nil {
+		return
+	}
+	ride.DeletedAt = nil
+	// State changed - invalidate cache
+	s.Invalidate(ride.ID)
+	logging.FromContext(ctx).Info("Ride restored", "ride_id", ride.ID)
 	return
 }
 
-func aggregateState(db *gorm.DB, ride *ridesData.Ride) (state *RideState, err error) {
-	newState := &RideState{}
+// Replay rebuilds the ride's state from it's events, ignoring the cached state
+func (s *RideService) Replay(ctx context.Context, ride *ridesData.Ride) (state *RideState, err error) {
+	return s.aggregateState(ctx, ride)
+}
+
+func (s *RideService) aggregateState(ctx context.Context, ride *ridesData.Ride) (state *RideState, err error) {
+	ctx, span := tracing.Start(ctx, "rides.aggregateState", attribute.Int64("ride.id", int64(ride.ID)))
+	defer func() { tracing.End(span, err) }()
+	newState := &RideState{clock: s.Clock}
 
-	dao := events.DAO{DB: db}
-	events, err := dao.EventFor(ride.ID, AggregateRoot)
+	start := time.Now()
+	events, err := s.DAO.EventFor(ctx, ride.ID, AggregateRoot)
 	if err != nil {
 		return nil, err
 	}
+	defer metrics.Replayed(AggregateRoot, start, len(events))
 
 	for _, event := range events {
 		switch event.Name {
@@ -147,17 +295,46 @@ func aggregateState(db *gorm.DB, ride *ridesData.Ride) (state *RideState, err er
 				return nil, err
 			}
 			e.Aggregate(newState)
+		case "RideStatusChanged":
+			e := &RideStatusChanged{}
+			err = e.FromDBEvent(event)
+			if err != nil {
+				return nil, err
+			}
+			e.Aggregate(newState)
+		case "RideRetired":
+			e := &RideRetired{}
+			err = e.FromDBEvent(event)
+			if err != nil {
+				return nil, err
+			}
+			e.Aggregate(newState)
+		case "RideRestored":
+			e := &RideRestored{}
+			err = e.FromDBEvent(event)
+			if err != nil {
+				return nil, err
+			}
+			e.Aggregate(newState)
+		default:
+			logging.FromContext(ctx).Warn("Unknown event received", "aggregate", AggregateRoot, "id", ride.ID, "event", event.Name)
 		}
 	}
 
-	newState.UpdatedAt = time.Now()
-	if newState.EstimatedWaitTill.After(time.Now()) {
+	newState.estimateMergedWaits(ride)
+	// Every queue of the ride is made before the state is cached, so readers never add one
+	for _, queueType := range ride.QueueTypes {
+		newState.queue(QueueType(queueType))
+	}
+
+	newState.UpdatedAt = newState.now()
+	if waitTill := newState.nextWaitTill(); waitTill.After(newState.UpdatedAt) {
 		// Since every at end of each batch we need to re-calculate wait time
-		timeRemainingToNextBatchStart := newState.EstimatedWaitTill.Sub(time.Now()) % ride.RideTime
-		Cache.SetWithTTL(strconv.Itoa(int(ride.ID)), newState, 0, timeRemainingToNextBatchStart)
+		timeRemainingToNextBatchStart := waitTill.Sub(newState.UpdatedAt) % ride.RideTime
+		s.Cache.SetWithTTL(strconv.Itoa(int(ride.ID)), newState, 0, timeRemainingToNextBatchStart)
 	} else {
 		// Cache till next person is on the queue
-		Cache.Set(strconv.Itoa(int(ride.ID)), newState, 0)
+		s.Cache.Set(strconv.Itoa(int(ride.ID)), newState, 0)
 	}
 	return newState, nil
 }
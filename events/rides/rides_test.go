@@ -1,12 +1,14 @@
 package rides_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/ristretto"
 	"github.com/jonboulle/clockwork"
 	"gitlab.com/therako/universal-studios/data/customers"
 	"gitlab.com/therako/universal-studios/data/events"
@@ -30,10 +32,6 @@ var (
 	)
 )
 
-func init() {
-	rides.Cache.Clear()
-}
-
 func testDB(name string) *gorm.DB {
 	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{Logger: gormLogger})
 	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
@@ -41,45 +39,52 @@ func testDB(name string) *gorm.DB {
 	gormDB.AutoMigrate(&events.Event{})
 	return gormDB
 }
+
+// testService returns a service on the DB with a cache of it's own, telling the time on a fake clock at
+// the given time
+func testService(db *gorm.DB, at time.Time) *rides.RideService {
+	return rides.NewService(db, rides.NewCache(1e3, 1e6), clockwork.NewFakeClockAt(at))
+}
+
 func TestE2ERideWaitEstimates(t *testing.T) {
 	db := testDB(t.Name())
 	ts := time.Now()
-	rides.Clock = clockwork.NewFakeClockAt(ts)
+	service := testService(db, ts)
 	ride := &ridesData.Ride{Model: models.Model{ID: 123}, Name: "ride1", Capacity: 4, RideTime: 10 * time.Minute}
 	customer := &customers.Customer{Model: models.Model{ID: 111}}
 
-	rides.LogCustomerJoinedRideQueue(db, ride, customer)
-	rides.LogCustomerJoinedRideQueue(db, ride, customer)
-	rides.LogCustomerJoinedRideQueue(db, ride, customer)
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
 
-	state, err := rides.GetCurrentState(db, ride)
+	state, err := service.GetCurrentState(context.Background(), ride)
 	assert.NilError(t, err)
 	assert.Equal(t, uint(3), state.QueueCount)
 	// expected wait still to be now since first batch is not full
 	assert.DeepEqual(t, ts, state.EstimatedWaitTill)
 
-	rides.LogCustomerJoinedRideQueue(db, ride, customer)
-	state, _ = rides.GetCurrentState(db, ride)
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	state, _ = service.GetCurrentState(context.Background(), ride)
 	// expected wait to be increased to future after a batch was filled
 	assert.DeepEqual(t, ts.Add(10*time.Minute), state.EstimatedWaitTill)
 
-	rides.LogCustomerJoinedRideQueue(db, ride, customer)
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	state, _ = rides.GetCurrentState(db, ride)
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	state, _ = service.GetCurrentState(context.Background(), ride)
 	// expected there to be no change in waits when adding customer and removing negates each other
 	assert.DeepEqual(t, ts.Add(10*time.Minute), state.EstimatedWaitTill)
 
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	state, _ = rides.GetCurrentState(db, ride)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	state, _ = service.GetCurrentState(context.Background(), ride)
 	// removing another use reduces the batch size and wait as well
 	assert.DeepEqual(t, ts, state.EstimatedWaitTill)
 
 	// Offload all customers in queue
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	rides.LogCustomerLeftRideQueue(db, ride, customer)
-	state, err = rides.GetCurrentState(db, ride)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	service.LogCustomerLeftRideQueue(context.Background(), ride, customer)
+	state, err = service.GetCurrentState(context.Background(), ride)
 	assert.NilError(t, err)
 	assert.DeepEqual(t, ts, state.EstimatedWaitTill)
 	assert.DeepEqual(t, uint(0), state.QueueCount)
@@ -88,27 +93,211 @@ func TestE2ERideWaitEstimates(t *testing.T) {
 func TestRideWaitEstimatesAfterBatchOfCustomerExits(t *testing.T) {
 	db := testDB(t.Name())
 	ts := time.Now()
-	rides.Clock = clockwork.NewFakeClockAt(ts)
+	service := testService(db, ts)
 	ride := &ridesData.Ride{Model: models.Model{ID: 123}, Name: "ride1", Capacity: 2, RideTime: 1 * time.Minute}
 	customer := &customers.Customer{Model: models.Model{ID: 111}}
 
 	for i := 0; i < 10; i++ {
-		rides.LogCustomerJoinedRideQueue(db, ride, customer)
+		service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
 	}
 
 	wait := ts.Add(time.Duration(ride.RideTime.Seconds()*10/2) * time.Second)
-	state, _ := rides.GetCurrentState(db, ride)
+	state, _ := service.GetCurrentState(context.Background(), ride)
 	assert.Equal(t, wait, state.EstimatedWaitTill)
 
 	// After a batch is over for the ride
 	ts = ts.Add(ride.RideTime)
 	wait = ts.Add(time.Duration(ride.RideTime.Seconds()*8/2) * time.Second)
-	state, _ = rides.GetCurrentState(db, ride)
+	state, _ = service.GetCurrentState(context.Background(), ride)
 	assert.Equal(t, wait, state.EstimatedWaitTill)
 
 	// After two batch is over for the ride
 	ts = ts.Add(ride.RideTime * 2)
 	wait = ts.Add(time.Duration(ride.RideTime.Seconds()*4/2) * time.Second)
-	state, _ = rides.GetCurrentState(db, ride)
+	state, _ = service.GetCurrentState(context.Background(), ride)
 	assert.Equal(t, wait, state.EstimatedWaitTill)
 }
+
+func TestSingleRiderWaitEstimates(t *testing.T) {
+	db := testDB(t.Name())
+	ts := time.Now()
+	service := testService(db, ts)
+	ride := &ridesData.Ride{Model: models.Model{ID: 124}, Name: "ride1", Capacity: 4, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider"}}
+	customer := &customers.Customer{Model: models.Model{ID: 111}}
+
+	err := service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.Virtual)
+	assert.Error(t, err, rides.ErrQueueTypeNotSupported.Error())
+	err = service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, "express")
+	assert.Error(t, err, rides.ErrUnknownQueueType.Error())
+
+	// 3 in standby leaves a seat in the current batch for a single rider
+	for i := 0; i < 3; i++ {
+		service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	}
+	service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.SingleRider)
+	state, err := service.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(3), state.QueueCount)
+	assert.Equal(t, uint(1), state.Queue(rides.SingleRider).QueueCount)
+	// single riders don't push back standby
+	assert.DeepEqual(t, ts, state.EstimatedWaitTill)
+	// the leftover seat is taken so next single rider waits a batch
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.Queue(rides.SingleRider).EstimatedWaitTill)
+
+	// 4 more single riders fill the next batch
+	for i := 0; i < 4; i++ {
+		service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.SingleRider)
+	}
+	state, _ = service.GetCurrentState(context.Background(), ride)
+	assert.DeepEqual(t, ts.Add(20*time.Minute), state.Queue(rides.SingleRider).EstimatedWaitTill)
+
+	// A single rider leaving frees up a seat in the next batch
+	service.LogCustomerLeftRideQueueOfType(context.Background(), ride, customer, rides.SingleRider)
+	state, _ = service.GetCurrentState(context.Background(), ride)
+	assert.Equal(t, uint(4), state.Queue(rides.SingleRider).QueueCount)
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.Queue(rides.SingleRider).EstimatedWaitTill)
+	assert.Equal(t, uint(3), state.QueueCount)
+}
+
+func TestPriorityWaitEstimatesUnderMixedLoad(t *testing.T) {
+	db := testDB(t.Name())
+	ts := time.Now()
+	service := testService(db, ts)
+	// 4 express per batch of 10, leaving 6 seats for standby while express has customers
+	ride := &ridesData.Ride{
+		Model: models.Model{ID: 125}, Name: "ride1", Capacity: 10, RideTime: 10 * time.Minute,
+		QueueTypes: models.StringList{"priority", "single_rider"}, PriorityMergeRatio: 4,
+	}
+	customer := &customers.Customer{Model: models.Model{ID: 111}}
+
+	for i := 0; i < 15; i++ {
+		service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	}
+	state, err := service.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	// Only standby, 15 fill a batch and half of the next
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.EstimatedWaitTill)
+
+	for i := 0; i < 6; i++ {
+		service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.Priority)
+	}
+	state, _ = service.GetCurrentState(context.Background(), ride)
+	assert.Equal(t, uint(6), state.Queue(rides.Priority).QueueCount)
+	// batch 1: 4 priority + 6 standby, batch 2: 2 priority + 8 standby, batch 3: 1 standby
+	assert.DeepEqual(t, ts.Add(20*time.Minute), state.EstimatedWaitTill)
+	// Next priority customer fits in the 2nd batch
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.Queue(rides.Priority).EstimatedWaitTill)
+
+	for i := 0; i < 10; i++ {
+		service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.SingleRider)
+	}
+	state, _ = service.GetCurrentState(context.Background(), ride)
+	// Single riders don't change the others
+	assert.DeepEqual(t, ts.Add(20*time.Minute), state.EstimatedWaitTill)
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.Queue(rides.Priority).EstimatedWaitTill)
+	// 9 single riders fill the 3rd batch, the 10th goes on the 4th
+	assert.DeepEqual(t, ts.Add(30*time.Minute), state.Queue(rides.SingleRider).EstimatedWaitTill)
+
+	// Priority customers leaving bring standby back to it's own estimate
+	for i := 0; i < 6; i++ {
+		service.LogCustomerLeftRideQueueOfType(context.Background(), ride, customer, rides.Priority)
+	}
+	state, _ = service.GetCurrentState(context.Background(), ride)
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.EstimatedWaitTill)
+	assert.DeepEqual(t, ts, state.Queue(rides.Priority).EstimatedWaitTill)
+}
+
+func TestPriorityMergeRatioIsCapped(t *testing.T) {
+	db := testDB(t.Name())
+	ts := time.Now()
+	service := testService(db, ts)
+	// Ratio can't starve standby, atmost capacity - 1 seats go to priority
+	ride := &ridesData.Ride{
+		Model: models.Model{ID: 126}, Name: "ride1", Capacity: 2, RideTime: 10 * time.Minute,
+		QueueTypes: models.StringList{"priority"}, PriorityMergeRatio: 5,
+	}
+	customer := &customers.Customer{Model: models.Model{ID: 111}}
+
+	for i := 0; i < 3; i++ {
+		service.LogCustomerJoinedRideQueueOfType(context.Background(), ride, customer, rides.Priority)
+	}
+	service.LogCustomerJoinedRideQueue(context.Background(), ride, customer)
+	state, err := service.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	// 1 priority per batch, so the next priority boards on the 4th batch
+	assert.DeepEqual(t, ts.Add(30*time.Minute), state.Queue(rides.Priority).EstimatedWaitTill)
+	// standby customer took the free seat in the 1st batch, next standby boards on the 2nd
+	assert.DeepEqual(t, ts.Add(10*time.Minute), state.EstimatedWaitTill)
+}
+
+func TestServicesAreIsolated(t *testing.T) {
+	ts := time.Now()
+	newService := func(name string, at time.Time) *rides.RideService {
+		cache, err := ristretto.NewCache(&ristretto.Config{NumCounters: 1e3, MaxCost: 1e6, BufferItems: 64})
+		assert.NilError(t, err)
+		return &rides.RideService{
+			DAO:   events.DAO{DB: testDB(name)},
+			Cache: cache,
+			Clock: clockwork.NewFakeClockAt(at),
+			Bus:   rides.NewBus(),
+		}
+	}
+	// Same ride in two tenants' DBs, on clocks an hour apart
+	tenant1 := newService(t.Name()+"1", ts)
+	tenant2 := newService(t.Name()+"2", ts.Add(time.Hour))
+	ride := &ridesData.Ride{Model: models.Model{ID: 127}, Name: "ride1", Capacity: 1, RideTime: 10 * time.Minute}
+	customer := &customers.Customer{Model: models.Model{ID: 111}}
+	changed, stop := tenant2.Bus.Watch(ride.ID)
+	defer stop()
+
+	for i := 0; i < 2; i++ {
+		assert.NilError(t, tenant1.LogCustomerJoinedRideQueue(context.Background(), ride, customer))
+	}
+	assert.NilError(t, tenant2.LogCustomerJoinedRideQueue(context.Background(), ride, customer))
+
+	state, err := tenant1.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(2), state.QueueCount)
+	assert.DeepEqual(t, ts.Add(20*time.Minute), state.EstimatedWaitTill)
+	state, err = tenant2.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, uint(1), state.QueueCount)
+	assert.DeepEqual(t, ts.Add(time.Hour+10*time.Minute), state.EstimatedWaitTill)
+
+	// Only tenant2's own change reached it's watchers
+	<-changed
+	tenant1.Invalidate(ride.ID)
+	select {
+	case <-changed:
+		t.Fatal("tenant1's change was published on tenant2's bus")
+	default:
+	}
+}
+
+func TestCachedStatesAreReadOnly(t *testing.T) {
+	db := testDB(t.Name())
+	ride := &ridesData.Ride{Model: models.Model{ID: 128}, Name: "ride1", Capacity: 2, RideTime: 10 * time.Minute, QueueTypes: models.StringList{"single_rider", "virtual"}}
+	db.Create(ride)
+	service := &rides.RideService{DAO: events.DAO{DB: db}, Cache: rides.NewCache(1e3, 1e6)}
+	assert.NilError(t, service.LogCustomerJoinedRideQueue(context.Background(), ride, &customers.Customer{Model: models.Model{ID: 112}}))
+
+	state, err := service.GetCurrentState(context.Background(), ride)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(state.Queues), "expected every queue of the ride to be made before caching")
+	assert.Equal(t, uint(0), state.Queue(rides.Priority).QueueCount)
+	assert.Equal(t, 2, len(state.Queues), "expected reading an unknown queue not to add it")
+
+	// Readers of the cached state apply it on their own copies of the ride at once, eg. list requests
+	// & the metrics scrape, run with -race
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			listed := *ride
+			state.Apply(&listed, time.Now())
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
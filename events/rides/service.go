@@ -0,0 +1,127 @@
+package rides
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"gorm.io/gorm"
+
+	"gitlab.com/therako/universal-studios/clock"
+	"gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/events"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+)
+
+// RideService logs & replays ride events with it's own DAO, cache, clock & bus, so tenants & tests
+// can each run on their own. The package functions are thin wrappers on NewService.
+type RideService struct {
+	DAO events.DAO
+	// Cache of ride states, nil turns caching off
+	Cache *ristretto.Cache
+	// Clock the real clock when nil
+	Clock clock.Clock
+	// Bus ride changes are published on, nil when nothing watches them
+	Bus *Bus
+}
+
+// NewService returns a service on the DB with the given cache & clock, & a bus of it's own
+func NewService(db *gorm.DB, cache *ristretto.Cache, clk clock.Clock) *RideService {
+	return &RideService{DAO: events.DAO{DB: db}, Cache: cache, Clock: clk, Bus: NewBus()}
+}
+
+// The package functions' cache & bus, made on first use
+var (
+	defaults     sync.Once
+	defaultCache *ristretto.Cache
+	defaultBus   *Bus
+)
+
+// DefaultService returns a service on the DB with the package's cache & bus on the real clock, the one
+// the package functions are thin wrappers on
+func DefaultService(db *gorm.DB) *RideService {
+	defaults.Do(func() {
+		defaultCache = NewCache(DefaultCacheNumCounters, DefaultCacheMaxCost)
+		defaultBus = NewBus()
+	})
+	return &RideService{DAO: events.DAO{DB: db}, Cache: defaultCache, Bus: defaultBus}
+}
+
+// WithDB returns a copy of the service on the DB, eg. a transaction
+func (s *RideService) WithDB(db *gorm.DB) *RideService {
+	withDB := *s
+	withDB.DAO = events.DAO{DB: db}
+	return &withDB
+}
+
+func (s *RideService) now() time.Time {
+	return clock.Or(s.Clock).Now()
+}
+
+// GetCurrentState from cache or calculate using events from DB
+func GetCurrentState(ctx context.Context, db *gorm.DB, ride *ridesData.Ride) (*RideState, error) {
+	return DefaultService(db).GetCurrentState(ctx, ride)
+}
+
+// LogCustomerJoinedRideQueue validates and adds customer in the standby queue of the ride
+func LogCustomerJoinedRideQueue(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer) error {
+	return DefaultService(db).LogCustomerJoinedRideQueue(ctx, ride, customer)
+}
+
+// LogCustomerJoinedRideQueueOfType validates and adds customer in the given queue type of the ride
+func LogCustomerJoinedRideQueueOfType(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer, queueType QueueType) error {
+	return DefaultService(db).LogCustomerJoinedRideQueueOfType(ctx, ride, customer, queueType)
+}
+
+// LogPartyJoinedRideQueue adds all members of a party in queue of the ride as a single event
+func LogPartyJoinedRideQueue(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, members []*customers.Customer) error {
+	return DefaultService(db).LogPartyJoinedRideQueue(ctx, ride, members)
+}
+
+// LogCustomerLeftRideQueue validates and removes customer from the standby queue of the ride
+func LogCustomerLeftRideQueue(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer) error {
+	return DefaultService(db).LogCustomerLeftRideQueue(ctx, ride, customer)
+}
+
+// LogCustomerLeftRideQueueOfType validates and removes customer from the given queue type of the ride
+func LogCustomerLeftRideQueueOfType(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, customer *customers.Customer, queueType QueueType) error {
+	return DefaultService(db).LogCustomerLeftRideQueueOfType(ctx, ride, customer, queueType)
+}
+
+// LogRideStatus marks the ride as down or back up
+func LogRideStatus(ctx context.Context, db *gorm.DB, ride *ridesData.Ride, down bool) error {
+	return DefaultService(db).LogRideStatus(ctx, ride, down)
+}
+
+// LogRideRetired soft deletes the ride taking it out of service, customers still in it's queues
+// are to be un-queued by the caller
+func LogRideRetired(ctx context.Context, db *gorm.DB, ride *ridesData.Ride) error {
+	return DefaultService(db).LogRideRetired(ctx, ride)
+}
+
+// LogRideRestored brings a retired ride back into service
+func LogRideRestored(ctx context.Context, db *gorm.DB, ride *ridesData.Ride) error {
+	return DefaultService(db).LogRideRestored(ctx, ride)
+}
+
+// Replay rebuilds the ride's state from it's events, ignoring the cached state
+func Replay(ctx context.Context, db *gorm.DB, ride *ridesData.Ride) (*RideState, error) {
+	return DefaultService(db).Replay(ctx, ride)
+}
+
+// List returns rides matching the filter with their current waiting times applied
+func List(ctx context.Context, db *gorm.DB, filter ridesData.Filter) ([]*ridesData.Ride, error) {
+	return DefaultService(db).List(ctx, filter)
+}
+
+// Watch returns a channel signalled each time the state of the ride changes on the package's bus,
+// along with a func to stop watching
+func Watch(rideID uint) (<-chan struct{}, func()) {
+	return DefaultService(nil).Bus.Watch(rideID)
+}
+
+// Invalidate drops the cached state of the ride & lets its watchers on the package's bus know it changed
+func Invalidate(rideID uint) {
+	DefaultService(nil).Invalidate(rideID)
+}
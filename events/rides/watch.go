@@ -0,0 +1,61 @@
+package rides
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Bus lets watchers of a ride know when it's state changes in this process, changes written by
+// other instances of the service are not seen
+type Bus struct {
+	sync.Mutex
+	byRide map[uint]map[chan struct{}]bool
+}
+
+// NewBus returns a bus with no watchers
+func NewBus() *Bus {
+	return &Bus{byRide: map[uint]map[chan struct{}]bool{}}
+}
+
+// Watch returns a channel signalled each time the state of the ride changes, along with a func
+// to stop watching. Signals are coalesced, so a slow reader sees a single pending signal.
+func (b *Bus) Watch(rideID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.Lock()
+	if b.byRide[rideID] == nil {
+		b.byRide[rideID] = map[chan struct{}]bool{}
+	}
+	b.byRide[rideID][ch] = true
+	b.Unlock()
+
+	return ch, func() {
+		b.Lock()
+		defer b.Unlock()
+		delete(b.byRide[rideID], ch)
+		if len(b.byRide[rideID]) == 0 {
+			delete(b.byRide, rideID)
+		}
+	}
+}
+
+// Publish signals the watchers of the ride, a nil bus has no watchers
+func (b *Bus) Publish(rideID uint) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	for ch := range b.byRide[rideID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Invalidate drops the cached state of the ride & lets its watchers know it changed
+func (s *RideService) Invalidate(rideID uint) {
+	s.Cache.Del(strconv.Itoa(int(rideID)))
+	s.Bus.Publish(rideID)
+}
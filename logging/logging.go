@@ -0,0 +1,46 @@
+// Package logging threads a structured logger through context.Context, so logs written deep in the
+// events & data packages carry the request ID & the customers or rides being acted on.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+// RequestIDKey is the attribute requests are logged with
+const RequestIDKey = "request_id"
+
+// New returns a JSON logger writing to w at the given level
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// FromContext returns the logger set on ctx, the default logger when none is set
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithContext returns a copy of ctx carrying the logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// With returns a copy of ctx whose logger adds the given attributes to every log, eg. "ride_id", 1
+func With(ctx context.Context, args ...interface{}) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
+// NewRequestID returns a random ID for requests which didn't come with one
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,50 +2,57 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"os"
 
-	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"gitlab.com/therako/universal-studios/api"
-	"gitlab.com/therako/universal-studios/data/customers"
-	"gitlab.com/therako/universal-studios/data/events"
-	"gitlab.com/therako/universal-studios/data/rides"
+	"gitlab.com/therako/universal-studios/cli"
+	"gitlab.com/therako/universal-studios/data/drivers"
+	"gitlab.com/therako/universal-studios/logging"
+	"gitlab.com/therako/universal-studios/tracing"
 )
 
 func main() {
-	fmt.Println("Welcome to Universal Studios")
+	// Commands other than serve write their results to stdout, so logs go to stderr
+	var logOutput io.Writer = os.Stdout
+	if cli.Name(os.Args[1:]) != cli.DefaultCommand {
+		logOutput = os.Stderr
+	}
 
+	slog.SetDefault(logging.New(logOutput, slog.LevelInfo))
 	ctx := context.Background()
 	cfg, err := api.GetConfig(ctx)
 	if err != nil {
-		log.Fatalln(ctx, err, "config-init-error")
+		fatal(ctx, err, "config-init-error")
+	}
+	level, _ := cfg.SlogLevel()
+	slog.SetDefault(logging.New(logOutput, level))
+
+	exporter, err := tracing.NewExporter(ctx, cfg.TraceExporter, cfg.TraceOTLPEndpoint)
+	if err != nil {
+		fatal(ctx, err, "trace-exporter")
 	}
+	tracerProvider := tracing.SetUp(exporter)
+	defer tracerProvider.Shutdown(ctx)
 
-	viper.SetDefault("POSTGRES_PORT", 5432)
-	dbDNS := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?statement_timeout=%d&connect_timeout=%d&sslmode=%s",
-		viper.GetString("POSTGRES_USER"),
-		viper.GetString("POSTGRES_PASSWORD"),
-		viper.GetString("POSTGRES_HOST"),
-		viper.GetUint("POSTGRES_PORT"),
-		viper.GetString("POSTGRES_DB"),
-		2000,
-		1,
-		"disable",
-	)
-	gormDB, err := gorm.Open(postgres.Open(dbDNS), &gorm.Config{})
+	gormDB, err := drivers.Open(cfg.DBDriver, cfg.DSN(), cfg.DBPool(), &gorm.Config{})
 	if err != nil {
-		log.Fatalln(ctx, err, "connecting-to-db")
+		fatal(ctx, err, "connecting-to-db")
 	}
 
-	gormDB.AutoMigrate(&rides.Ride{})
-	gormDB.AutoMigrate(&customers.Customer{})
-	gormDB.AutoMigrate(&events.Event{})
+	err = cli.Run(ctx, cli.Env{Config: cfg, DB: gormDB, In: os.Stdin, Out: os.Stdout}, os.Args[1:])
+	if err != nil {
+		// fatal exits skipping the deferred flush of spans
+		tracerProvider.Shutdown(ctx)
+		fatal(ctx, err, cli.Name(os.Args[1:]))
+	}
+}
 
-	gin.SetMode(gin.ReleaseMode)
-	router := api.New(ctx, cfg, gormDB)
-	router.Run(fmt.Sprintf(":%d", cfg.HTTPPort))
+// fatal logs the error & exits
+func fatal(ctx context.Context, err error, msg string) {
+	logging.FromContext(ctx).Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -0,0 +1,77 @@
+// Package metrics holds the Prometheus collectors recorded by the data, events & api packages.
+// Collectors aren't registered here, each API router registers them on it's own registry.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "studios"
+
+// Cache lookup results
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+var (
+	// EventsAppended counts events added to the DB by aggregate root & event name
+	EventsAppended = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_appended_total",
+		Help:      "Events appended to the event store.",
+	}, []string{"aggregate", "name"})
+
+	// ReplayDuration of aggregating an aggregate's state from it's events
+	ReplayDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "aggregate_replay_duration_seconds",
+		Help:      "Time taken to replay an aggregate's events into it's state.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"aggregate"})
+
+	// ReplayEvents is the no of events replayed to aggregate a state
+	ReplayEvents = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "aggregate_replay_events",
+		Help:      "Events replayed to aggregate a state.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"aggregate"})
+
+	// CacheLookups counts state cache hits & misses, the hit ratio is hits over all lookups
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "State cache lookups by aggregate & result (hit or miss).",
+	}, []string{"aggregate", "result"})
+
+	// HTTPDuration of handling requests by route, the route is the path template eg. /v1/rides/:id
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Collectors returns all collectors of the package to be registered
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{EventsAppended, ReplayDuration, ReplayEvents, CacheLookups, HTTPDuration}
+}
+
+// CacheLookup records a state cache hit or miss for the aggregate
+func CacheLookup(aggregate string, found bool) {
+	result := CacheMiss
+	if found {
+		result = CacheHit
+	}
+	CacheLookups.WithLabelValues(aggregate, result).Inc()
+}
+
+// Replayed records the time & no of events taken to aggregate a state, since the start time
+func Replayed(aggregate string, start time.Time, events int) {
+	ReplayDuration.WithLabelValues(aggregate).Observe(time.Since(start).Seconds())
+	ReplayEvents.WithLabelValues(aggregate).Observe(float64(events))
+}
@@ -38,7 +38,9 @@ def get_rides():
 
 def add_some_customers(nos: int):
     for i in range(1, nos):
-        response = requests.request("POST", uri + "/customer/enter")
+        response = requests.request("POST", uri + "/ticket/add")
+        code = response.json()["code"]
+        response = requests.request("POST", uri + "/customer/enter", data={"code": code})
 
 
 def get_customers():
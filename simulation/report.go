@@ -0,0 +1,148 @@
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+)
+
+// Accuracy of the waits quoted to guests who boarded against the waits they actually had
+type Accuracy struct {
+	Samples    int           `json:"samples"`
+	MeanQuoted time.Duration `json:"mean_quoted"`
+	MeanActual time.Duration `json:"mean_actual"`
+	// MeanError is the mean of quoted - actual, positive when quotes are too long
+	MeanError         time.Duration `json:"mean_error"`
+	MeanAbsoluteError time.Duration `json:"mean_absolute_error"`
+	MaxAbsoluteError  time.Duration `json:"max_absolute_error"`
+	// WithinTolerance is the share of quotes off by no more than the config's tolerance
+	WithinTolerance float64 `json:"within_tolerance"`
+
+	tolerance                     time.Duration
+	quoted, actual, off, absolute float64
+	within                        int
+}
+
+func (a *Accuracy) add(quoted time.Duration, actual time.Duration) {
+	off := quoted - actual
+	absolute := off
+	if absolute < 0 {
+		absolute = -absolute
+	}
+	a.Samples++
+	a.quoted += float64(quoted)
+	a.actual += float64(actual)
+	a.off += float64(off)
+	a.absolute += float64(absolute)
+	if absolute > a.MaxAbsoluteError {
+		a.MaxAbsoluteError = absolute
+	}
+	if absolute <= a.tolerance {
+		a.within++
+	}
+	a.summarise()
+}
+
+func (a *Accuracy) merge(other Accuracy) {
+	a.Samples += other.Samples
+	a.quoted += other.quoted
+	a.actual += other.actual
+	a.off += other.off
+	a.absolute += other.absolute
+	a.within += other.within
+	if other.MaxAbsoluteError > a.MaxAbsoluteError {
+		a.MaxAbsoluteError = other.MaxAbsoluteError
+	}
+	a.summarise()
+}
+
+func (a *Accuracy) summarise() {
+	a.MeanQuoted = mean(a.quoted, a.Samples)
+	a.MeanActual = mean(a.actual, a.Samples)
+	a.MeanError = mean(a.off, a.Samples)
+	a.MeanAbsoluteError = mean(a.absolute, a.Samples)
+	if a.Samples > 0 {
+		a.WithinTolerance = float64(a.within) / float64(a.Samples)
+	}
+}
+
+// RideReport of the guests who picked a ride
+type RideReport struct {
+	Name string `json:"name"`
+	// Arrivals who picked the ride, they either queued or balked at the quote
+	Arrivals int `json:"arrivals"`
+	Queued   int `json:"queued"`
+	Balked   int `json:"balked"`
+	Reneged  int `json:"reneged"`
+	// StillQueueing when the simulation ended, they're in neither boarded nor reneged
+	StillQueueing int `json:"still_queueing"`
+	Accuracy
+}
+
+// Boarded is the no of guests who got on the ride
+func (r RideReport) Boarded() int {
+	return r.Samples
+}
+
+// Report of a simulation run
+type Report struct {
+	Seed  int64        `json:"seed"`
+	Start time.Time    `json:"start"`
+	End   time.Time    `json:"end"`
+	Rides []RideReport `json:"rides"`
+	// Total of all the rides
+	Total RideReport `json:"total"`
+}
+
+func newReport(config Config) *Report {
+	report := &Report{Seed: config.Seed, Start: config.Start, Total: RideReport{Name: "Total"}}
+	report.Total.tolerance = config.Tolerance
+	for _, ride := range config.Rides {
+		rideReport := RideReport{Name: ride.Name}
+		rideReport.tolerance = config.Tolerance
+		report.Rides = append(report.Rides, rideReport)
+	}
+	return report
+}
+
+// total sums up the ride reports
+func (r *Report) total() {
+	for _, ride := range r.Rides {
+		r.Total.Arrivals += ride.Arrivals
+		r.Total.Queued += ride.Queued
+		r.Total.Balked += ride.Balked
+		r.Total.Reneged += ride.Reneged
+		r.Total.StillQueueing += ride.StillQueueing
+		r.Total.merge(ride.Accuracy)
+	}
+}
+
+// Write the report as a table of rides
+func (r *Report) Write(w io.Writer) error {
+	fmt.Fprintf(w, "Simulated %s from %s with seed %d\n\n", r.End.Sub(r.Start), r.Start.Format(time.RFC3339), r.Seed)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "RIDE\tARRIVALS\tQUEUED\tBALKED\tRENEGED\tBOARDED\tQUEUEING\tQUOTED\tACTUAL\tERROR\tABS ERROR\tMAX ERROR\tWITHIN\t")
+	rows := append(append([]RideReport{}, r.Rides...), r.Total)
+	for _, ride := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%.0f%%\t\n",
+			ride.Name, ride.Arrivals, ride.Queued, ride.Balked, ride.Reneged, ride.Boarded(), ride.StillQueueing,
+			rounded(ride.MeanQuoted), rounded(ride.MeanActual), rounded(ride.MeanError),
+			rounded(ride.MeanAbsoluteError), rounded(ride.MaxAbsoluteError), ride.WithinTolerance*100)
+	}
+	return tw.Flush()
+}
+
+// mean of a total of durations over n samples
+func mean(total float64, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return time.Duration(math.Round(total / float64(n)))
+}
+
+// rounded formats the duration to the second, which is as precise as waits are quoted
+func rounded(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,343 @@
+// Package simulation runs a deterministic day at the park through the event services, guests
+// arriving at random, picking rides by popularity & quoted wait & abandoning long queues, to measure
+// how close the quoted waits were to the waits guests actually had.
+//
+// Rides board the head of their queue every ride time, so the actual waits come from the simulated
+// queues & not from the estimates. A run logs events through it's own services on a fake clock &
+// without state caches, so it can run alongside serving or other runs on another DB.
+package simulation
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gorm.io/gorm"
+
+	customersData "gitlab.com/therako/universal-studios/data/customers"
+	"gitlab.com/therako/universal-studios/data/events"
+	ridesData "gitlab.com/therako/universal-studios/data/rides"
+	customersEvents "gitlab.com/therako/universal-studios/events/customers"
+	ridesEvents "gitlab.com/therako/universal-studios/events/rides"
+)
+
+// Errors
+var (
+	ErrInvalidConfig = errors.New("Invalid simulation config, expected rides with a capacity & ride time, arrivals & a duration")
+)
+
+// Ride simulated
+type Ride struct {
+	Name     string
+	Capacity uint
+	RideTime time.Duration
+	// Popularity weighs how likely guests are to pick the ride
+	Popularity float64
+}
+
+// Config of a simulation run, the same config & seed always give the same report
+type Config struct {
+	Seed  int64
+	Start time.Time
+	// Duration guests arrive for, queues left at the end aren't counted
+	Duration time.Duration
+	// ArrivalsPerHour is the mean rate guests arrive at, arrivals are a Poisson process
+	ArrivalsPerHour float64
+	Rides           []Ride
+	// MeanPatience is the mean of the exponentially distributed time guests are willing to wait,
+	// guests balk at quotes longer than it & renege once they've waited longer. Zero means guests
+	// never abandon.
+	MeanPatience time.Duration
+	// WaitSensitivity is how much quoted waits put guests off a ride, a ride's popularity is divided
+	// by 1 + WaitSensitivity * the quoted wait in hours
+	WaitSensitivity float64
+	// Tolerance is how far off a quote can be & still count as accurate in the report
+	Tolerance time.Duration
+}
+
+// DefaultConfig returns a busy 10 hour day at a park of 4 rides
+func DefaultConfig() Config {
+	return Config{
+		Seed:            1,
+		Start:           time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC),
+		Duration:        10 * time.Hour,
+		ArrivalsPerHour: 600,
+		Rides: []Ride{
+			{Name: "Jurassic Park", Capacity: 20, RideTime: 6 * time.Minute, Popularity: 4},
+			{Name: "Revenge of the Mummy", Capacity: 16, RideTime: 4 * time.Minute, Popularity: 3},
+			{Name: "Transformers", Capacity: 12, RideTime: 5 * time.Minute, Popularity: 2},
+			{Name: "Carousel", Capacity: 30, RideTime: 3 * time.Minute, Popularity: 1},
+		},
+		MeanPatience:    45 * time.Minute,
+		WaitSensitivity: 1,
+		Tolerance:       5 * time.Minute,
+	}
+}
+
+func (c Config) valid() bool {
+	if len(c.Rides) == 0 || c.ArrivalsPerHour <= 0 || c.Duration <= 0 || c.MeanPatience < 0 {
+		return false
+	}
+	for _, ride := range c.Rides {
+		if ride.Capacity == 0 || ride.RideTime <= 0 || ride.Popularity < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// guest in a ride queue
+type guest struct {
+	customer *customersData.Customer
+	ride     int
+	joinedAt time.Time
+	quoted   time.Duration
+	gone     bool
+}
+
+// Kinds of happenings
+const (
+	arrival = iota
+	dispatch
+	renege
+)
+
+// happening is something due to happen at a simulated time
+type happening struct {
+	at    time.Time
+	seq   int
+	kind  int
+	ride  int
+	guest *guest
+}
+
+// agenda orders happenings by time & the order they were scheduled in
+type agenda []*happening
+
+func (a agenda) Len() int { return len(a) }
+func (a agenda) Less(i, j int) bool {
+	if a[i].at.Equal(a[j].at) {
+		return a[i].seq < a[j].seq
+	}
+	return a[i].at.Before(a[j].at)
+}
+func (a agenda) Swap(i, j int)       { a[i], a[j] = a[j], a[i] }
+func (a *agenda) Push(x interface{}) { *a = append(*a, x.(*happening)) }
+func (a *agenda) Pop() interface{} {
+	old := *a
+	h := old[len(old)-1]
+	*a = old[:len(old)-1]
+	return h
+}
+
+// run is the state of a simulation run
+type run struct {
+	ctx             context.Context
+	db              *gorm.DB
+	config          Config
+	clock           clockwork.FakeClock
+	rideService     *ridesEvents.RideService
+	customerService *customersEvents.CustomerService
+	random          *rand.Rand
+	agenda          agenda
+	seq             int
+	rides           []*ridesData.Ride
+	// queues of guests in each ride's line, in the order they board
+	queues [][]*guest
+	report *Report
+}
+
+// Run simulates the config against the DB, adding the rides, customers & their events to it, &
+// returns how accurate the quoted waits were. The DB is expected to be migrated & is best empty.
+func Run(ctx context.Context, db *gorm.DB, config Config) (*Report, error) {
+	if !config.valid() {
+		return nil, ErrInvalidConfig
+	}
+
+	clock := clockwork.NewFakeClockAt(config.Start)
+	// Cached states expire in real time, without them every state is replayed at the simulated time.
+	// Nothing watches the simulated rides so there's no bus either.
+	rideService := &ridesEvents.RideService{DAO: events.DAO{DB: db}, Clock: clock}
+	customerService := &customersEvents.CustomerService{
+		DAO:    customersData.DAO{DB: db, Clock: clock},
+		Events: events.DAO{DB: db},
+		Clock:  clock,
+		Rides:  rideService,
+	}
+
+	r := &run{
+		ctx:             ctx,
+		db:              db,
+		config:          config,
+		clock:           clock,
+		rideService:     rideService,
+		customerService: customerService,
+		random:          rand.New(rand.NewSource(config.Seed)),
+		queues:          make([][]*guest, len(config.Rides)),
+		report:          newReport(config),
+	}
+	err := r.simulate()
+	if err != nil {
+		return nil, err
+	}
+	return r.report, nil
+}
+
+func (r *run) simulate() error {
+	dao := ridesData.DAO{DB: r.db.WithContext(r.ctx)}
+	for i, config := range r.config.Rides {
+		ride := &ridesData.Ride{Name: config.Name, Capacity: config.Capacity, RideTime: config.RideTime}
+		err := dao.Add(ride)
+		if err != nil {
+			return err
+		}
+		r.rides = append(r.rides, ride)
+		r.schedule(&happening{at: r.config.Start.Add(ride.RideTime), kind: dispatch, ride: i})
+	}
+	r.schedule(&happening{at: r.config.Start.Add(r.interArrival()), kind: arrival})
+
+	end := r.config.Start.Add(r.config.Duration)
+	for r.agenda.Len() > 0 {
+		h := heap.Pop(&r.agenda).(*happening)
+		if h.at.After(end) {
+			break
+		}
+		r.clock.Advance(h.at.Sub(r.clock.Now()))
+
+		var err error
+		switch h.kind {
+		case arrival:
+			err = r.arrive()
+		case dispatch:
+			r.board(h.ride)
+		case renege:
+			err = r.renege(h.guest)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, queue := range r.queues {
+		r.report.Rides[i].StillQueueing = len(queue)
+	}
+	r.report.End = end
+	r.report.total()
+	return nil
+}
+
+func (r *run) schedule(h *happening) {
+	r.seq++
+	h.seq = r.seq
+	heap.Push(&r.agenda, h)
+}
+
+// interArrival is the time till the next guest arrives, exponentially distributed for Poisson arrivals
+func (r *run) interArrival() time.Duration {
+	return time.Duration(r.random.ExpFloat64() / r.config.ArrivalsPerHour * float64(time.Hour))
+}
+
+// patience is how long a guest is willing to wait, 0 for guests who never abandon
+func (r *run) patience() time.Duration {
+	if r.config.MeanPatience == 0 {
+		return 0
+	}
+	return time.Duration(r.random.ExpFloat64() * float64(r.config.MeanPatience))
+}
+
+// arrive quotes the waits of every ride to a new guest, who picks one & queues unless the quote is
+// longer than they're willing to wait
+func (r *run) arrive() error {
+	now := r.clock.Now()
+	r.schedule(&happening{at: now.Add(r.interArrival()), kind: arrival})
+
+	quotes := make([]time.Duration, len(r.rides))
+	weights := make([]float64, len(r.rides))
+	total := 0.0
+	for i, ride := range r.rides {
+		state, err := r.rideService.GetCurrentState(r.ctx, ride)
+		if err != nil {
+			return err
+		}
+		quotes[i] = state.WaitTime(now)
+		weights[i] = r.config.Rides[i].Popularity / (1 + r.config.WaitSensitivity*quotes[i].Hours())
+		total += weights[i]
+	}
+	choice := len(r.rides) - 1
+	pick := r.random.Float64() * total
+	for i, weight := range weights {
+		if pick < weight {
+			choice = i
+			break
+		}
+		pick -= weight
+	}
+	rideReport := &r.report.Rides[choice]
+	rideReport.Arrivals++
+
+	patience := r.patience()
+	if patience > 0 && quotes[choice] > patience {
+		rideReport.Balked++
+		return nil
+	}
+
+	customer := &customersData.Customer{}
+	err := r.db.WithContext(r.ctx).Create(customer).Error
+	if err != nil {
+		return err
+	}
+	err = r.customerService.LogCustomerInQueue(r.ctx, customer, r.rides[choice])
+	if err != nil {
+		return err
+	}
+	g := &guest{customer: customer, ride: choice, joinedAt: now, quoted: quotes[choice]}
+	r.queues[choice] = append(r.queues[choice], g)
+	rideReport.Queued++
+	if patience > 0 {
+		r.schedule(&happening{at: now.Add(patience), kind: renege, guest: g})
+	}
+	return nil
+}
+
+// board boards the head of the ride's queue & schedules the next boarding a ride time later
+func (r *run) board(i int) {
+	now := r.clock.Now()
+	ride := r.rides[i]
+	r.schedule(&happening{at: now.Add(ride.RideTime), kind: dispatch, ride: i})
+
+	boarding := int(ride.Capacity)
+	if boarding > len(r.queues[i]) {
+		boarding = len(r.queues[i])
+	}
+	for _, g := range r.queues[i][:boarding] {
+		g.gone = true
+		r.report.Rides[i].add(g.quoted, now.Sub(g.joinedAt))
+	}
+	r.queues[i] = r.queues[i][boarding:]
+}
+
+// renege takes a guest who ran out of patience out of the queue, unless they boarded already
+func (r *run) renege(g *guest) error {
+	if g.gone {
+		return nil
+	}
+	g.gone = true
+	queue := r.queues[g.ride]
+	for i := range queue {
+		if queue[i] == g {
+			r.queues[g.ride] = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	r.report.Rides[g.ride].Reneged++
+
+	err := r.customerService.LogCustomerLeftAQueue(r.ctx, g.customer)
+	if err == customersEvents.ErrCustomerCantBeUnQueue {
+		// The estimate had them on the ride already
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package simulation_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/therako/universal-studios/data/migrations"
+	"gitlab.com/therako/universal-studios/simulation"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gotest.tools/v3/assert"
+)
+
+func testDB(t *testing.T, name string) *gorm.DB {
+	gormDB, _ := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory", name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	gormDB.Exec("PRAGMA foreign_keys = ON") // SQLite defaults to `foreign_keys = off'`
+	sqlDB, err := gormDB.DB()
+	assert.NilError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Every connection opens a new in-memory DB
+	_, err = migrations.New(gormDB).Up(context.Background(), 0)
+	assert.NilError(t, err)
+	return gormDB
+}
+
+func testConfig() simulation.Config {
+	config := simulation.DefaultConfig()
+	config.Duration = 20 * time.Minute
+	config.ArrivalsPerHour = 900
+	return config
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	report, err := simulation.Run(ctx, testDB(t, t.Name()), testConfig())
+
+	assert.NilError(t, err)
+	total := report.Total
+	assert.Assert(t, total.Arrivals > 0)
+	assert.Equal(t, total.Arrivals, total.Queued+total.Balked)
+	assert.Equal(t, total.Queued, total.Boarded()+total.Reneged+total.StillQueueing)
+	assert.Assert(t, total.Boarded() > 0)
+	assert.Assert(t, total.MeanQuoted > 0, "expected queues to build up")
+	assert.Assert(t, total.WithinTolerance >= 0 && total.WithinTolerance <= 1)
+
+	t.Run("expected the same seed to give the same report", func(t *testing.T) {
+		again, err := simulation.Run(ctx, testDB(t, t.Name()), testConfig())
+		assert.NilError(t, err)
+		written, writtenAgain := &bytes.Buffer{}, &bytes.Buffer{}
+		report.Write(written)
+		again.Write(writtenAgain)
+		assert.Equal(t, written.String(), writtenAgain.String())
+
+		config := testConfig()
+		config.Seed = 2
+		other, err := simulation.Run(ctx, testDB(t, t.Name()+"Other"), config)
+		assert.NilError(t, err)
+		assert.Assert(t, other.Total.Arrivals != total.Arrivals || other.Total.MeanActual != total.MeanActual)
+	})
+
+	t.Run("expected impatient guests to abandon queues", func(t *testing.T) {
+		config := testConfig()
+		config.MeanPatience = time.Minute
+		impatient, err := simulation.Run(ctx, testDB(t, t.Name()), config)
+		assert.NilError(t, err)
+		assert.Assert(t, impatient.Total.Reneged > total.Reneged)
+		assert.Assert(t, impatient.Total.Boarded() < total.Boarded())
+
+		config.MeanPatience = 0
+		patient, err := simulation.Run(ctx, testDB(t, t.Name()+"Patient"), config)
+		assert.NilError(t, err)
+		assert.Equal(t, 0, patient.Total.Balked+patient.Total.Reneged)
+	})
+
+	t.Run("expected the report to be written as a table", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NilError(t, report.Write(out))
+		assert.Assert(t, strings.Contains(out.String(), "Jurassic Park"))
+		assert.Equal(t, len(report.Rides)+4, strings.Count(out.String(), "\n"))
+	})
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	config := simulation.DefaultConfig()
+	config.Rides[0].Capacity = 0
+
+	_, err := simulation.Run(context.Background(), nil, config)
+
+	assert.Equal(t, simulation.ErrInvalidConfig, err)
+}